@@ -2,83 +2,354 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 
 	"github.com/dimdasci/seek/internal/client/openai"
 	"github.com/dimdasci/seek/internal/config"
+	"github.com/dimdasci/seek/internal/llm"
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/progress"
+	"github.com/dimdasci/seek/internal/review"
+	"github.com/dimdasci/seek/internal/service/relevance"
 	"github.com/dimdasci/seek/internal/service/search"
+	"github.com/dimdasci/seek/internal/service/verify"
 	"github.com/dimdasci/seek/internal/service/webread"
 	"github.com/dimdasci/seek/internal/service/websearch"
+	"github.com/dimdasci/seek/internal/session"
+	"github.com/dimdasci/seek/internal/usage"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
-var outputFile string
+var (
+	outputFile    string
+	verifyClaims  bool
+	resumeSession string
+	planFile      string
+	interactive   bool
+)
 
 // answerCmd represents the answer command
 var answerCmd = &cobra.Command{
 	Use:   "answer [question]",
 	Short: "Search for an answer to your question",
-	Args:  cobra.MinimumNArgs(1),
-	Run:   runAnswerCmd,
+	Long: `Search for an answer to your question.
+
+Every run is saved as a session. If the run fails or is interrupted,
+continue it with --resume and the session ID printed at the start.
+
+Use --plan to run a plan saved by seek plan instead of building a new one.
+The question defaults to the search query of the plan.
+
+Use -i to review the plan before the search starts: approve it, drop steps,
+edit queries, add steps or ask the planner to revise the plan.
+
+Use --format json to get a JSON document of the run instead of the report:
+the plan, the steps with their queries, the sources with their relevance
+and key points, the report in markdown and the token usage.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		switch {
+		case resumeSession != "":
+			return cobra.NoArgs(cmd, args)
+		case planFile != "":
+			return cobra.ArbitraryArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: runAnswerCmd,
 }
 
 func init() {
 	rootCmd.AddCommand(answerCmd)
 
-	answerCmd.Flags().StringVarP(&outputFile, "output", "o", "", "output file for the result in markdown format")
+	answerCmd.Flags().StringVarP(&outputFile, "output", "o", "", "output file for the result")
+	answerCmd.Flags().BoolVar(&verifyClaims, "verify", false, "check the report claims against the fetched sources")
+	answerCmd.Flags().StringVar(&resumeSession, "resume", "", "resume the session with the ID")
+	answerCmd.Flags().StringVar(&planFile, "plan", "", "run the search plan from the JSON or YAML file")
+	answerCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "review the search plan before execution")
+	answerCmd.Flags().StringVar(&progressFormat, "progress", progressText, "progress output to stderr: text, json or none")
+	answerCmd.Flags().StringVar(&outputFormat, "format", formatMarkdown, "format of the result: markdown or json")
+	answerCmd.MarkFlagsMutuallyExclusive("resume", "plan")
+	answerCmd.MarkFlagsMutuallyExclusive("resume", "interactive")
 }
 
 func runAnswerCmd(cmd *cobra.Command, args []string) {
-	question := strings.Join(args, " ")
-	logger.Info("Searching for an answer", zap.String("question", question))
-
 	cfg := config.Get()
-	if cfg.OpenAI.APIKey == "" {
-		logger.Error("OpenAI API key not found. Exiting...")
-		fmt.Println("OpenAI API key not found. Exiting...")
+
+	if outputFormat != formatMarkdown && outputFormat != formatJSON {
+		fmt.Fprintf(os.Stderr, "Unknown output format: %s\n", outputFormat)
+		exitCode = exitFailure
 		return
 	}
 
+	observer, stopProgress, err := newObserver(progressFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exitCode = exitFailure
+		return
+	}
+	defer stopProgress()
+
+	// Start a new session or resume the saved one
+	sess, err := openSession(session.NewStore(cfg.Session.Dir), args)
+	if err != nil {
+		logger.Error("Failed to open session", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to open session: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+	logger.Info("Searching for an answer",
+		zap.String("session", sess.ID),
+		zap.String("question", sess.Question))
+	fmt.Fprintf(os.Stderr, "Session %s\n", sess.ID)
+
 	// Initialize clients and services
-	openaiClient, err := openai.NewClient(
-		cfg.OpenAI.APIKey,
-		logger,
-		cfg.OpenAI.Reasoning.Model,
-		cfg.OpenAI.Completion.Model,
-		cfg.OpenAI.Reasoning.Timeout,
-		cfg.OpenAI.Completion.Timeout,
-		cfg.OpenAI.Reasoning.MaxTokens,
-		cfg.OpenAI.Completion.MaxTokens,
-	)
+	meter := newMeter(cfg)
+	llmClient, err := newLLM(cfg, meter)
 	if err != nil {
-		logger.Error("Failed to create OpenAI client", zap.Error(err))
-		fmt.Printf("Failed to create OpenAI client: %v\n", err)
+		logger.Error("Failed to create LLM client", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to create LLM client: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+	searchService, err := newSearchService(cfg, llmClient, newWebReader(cfg), verifyClaims, observer, meter)
+	if err != nil {
+		logger.Error("Failed to create search service", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to create search service: %v\n", err)
+		exitCode = exitFailure
 		return
 	}
-	webSearcher := websearch.NewTavilySearchService(logger, cfg.WebSearch.Tavily.Timeout)
-	webReader := webread.NewReadService(logger, cfg.WebReader.Timeout)
-	searchService := search.NewService(openaiClient, webSearcher, webReader, logger)
 
-	// Search for the answer
-	answer, err := searchService.Search(context.Background(), question)
+	// Review the plan before any search tokens are spent
+	if interactive {
+		if err := reviewPlan(context.Background(), llmClient, sess); err != nil {
+			logger.Error("Failed to review search plan", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Failed to review search plan: %v\n", err)
+			exitCode = exitFailure
+			return
+		}
+	}
+
+	// Search for the answer, Ctrl-C stops the run keeping the saved progress
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// stream the final report to the terminal as it is written
+	var stream *reportStream
+	if outputFormat == formatMarkdown && isTerminal(os.Stdout) {
+		stream = &reportStream{w: os.Stdout}
+	}
+
+	result, err := searchService.Search(ctx, sess, stream.writer())
+	stopProgress()
 	if err != nil {
+		if stream.streamed() {
+			fmt.Println()
+		}
 		logger.Error("Failed to get answer", zap.Error(err))
-		fmt.Printf("Failed to get answer: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Failed to get answer: %v\n", err)
+		fmt.Fprint(os.Stderr, meter.Summary())
+		fmt.Fprintf(os.Stderr, "Resume with: seek answer --resume %s\n", sess.ID)
+		exitCode = exitFailure
 		return
 	}
 
-	// Handle output
+	if outputFormat == formatJSON {
+		doc := searchService.Document(sess, result)
+		doc.Usage = meter.Report()
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			logger.Error("Failed to encode answer", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Failed to encode answer: %v\n", err)
+			exitCode = exitFailure
+			return
+		}
+		writeAnswer(string(data), outputFile, false)
+	} else {
+		stream.finish(result.Report)
+		writeAnswer(result.Report, outputFile, stream.streamed())
+	}
+	fmt.Fprint(os.Stderr, meter.Summary())
+
+	// the steps not completed are run again on resume
+	if result.Partial() {
+		fmt.Fprintf(os.Stderr, "Some research steps are not completed, resume with: seek answer --resume %s\n", sess.ID)
+		exitCode = exitPartial
+	}
+}
+
+// newSearchService creates the search service with the web searcher from the config
+// and the web reader. Claims of the reports are checked if verifyClaims is set.
+// Progress of the runs is sent to the observer, if any.
+// The run degrades to stay within the budget of the meter.
+func newSearchService(
+	cfg *config.Config,
+	llmClient llm.LLM,
+	webReader webread.WebReader,
+	verifyClaims bool,
+	observer progress.Observer,
+	meter *usage.Meter) (*search.Service, error) {
+	webSearcher, err := websearch.NewWebSearcher(logger, cfg.WebSearch.Provider, cfg.WebSearch.Retry.Policy())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create web searcher: %w", err)
+	}
+	var verifier search.Verifier
+	if verifyClaims {
+		verifier = verify.NewService(llmClient, webReader, logger, cfg.Search.Concurrency)
+	}
+	prefilter, err := newPrefilter(cfg, meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create relevance prefilter: %w", err)
+	}
+	deepening := search.Deepening{
+		MaxDepth:   cfg.Search.Deepening.MaxDepth,
+		MaxQueries: cfg.Search.Deepening.MaxQueries,
+	}
+	return search.NewService(llmClient, webSearcher, webReader, verifier, prefilter, observer, logger, cfg.Search.Concurrency, deepening, meter), nil
+}
+
+// searchFactory returns the function creating the search service of a single
+// research run of a long-running server, with the shared web reader.
+// Every run gets its own meter, so the budget limits a single run.
+func searchFactory(cfg *config.Config, webReader webread.WebReader) func(progress.Observer, bool) (*search.Service, *usage.Meter, error) {
+	return func(observer progress.Observer, verify bool) (*search.Service, *usage.Meter, error) {
+		meter := newMeter(cfg)
+		llmClient, err := newLLM(cfg, meter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create LLM client: %w", err)
+		}
+		searchService, err := newSearchService(cfg, llmClient, webReader, verify, observer, meter)
+		if err != nil {
+			return nil, nil, err
+		}
+		return searchService, meter, nil
+	}
+}
+
+// newWebReader creates the web reader of the CLI runs from the config.
+func newWebReader(cfg *config.Config) webread.WebReader {
+	return webread.NewReadService(logger, cfg.WebReader.Timeout, cfg.WebReader.Retry.Policy())
+}
+
+// writeAnswer writes the answer to the output file, or prints it if the file is not set.
+// The answer already streamed to the terminal is not printed again.
+func writeAnswer(answer string, outputFile string, streamed bool) {
 	if outputFile != "" {
-		if err := os.WriteFile(outputFile, []byte(answer), 0644); err != nil {
+		if err := writeFile(outputFile, []byte(answer)); err != nil {
 			logger.Error("Failed to write to file", zap.Error(err))
-			fmt.Printf("failed to write to file: %v", err)
+			fmt.Fprintf(os.Stderr, "Failed to write to file: %v\n", err)
+			exitCode = exitFailure
+		} else {
+			fmt.Fprintf(os.Stderr, "Answer saved to: %s\n", outputFile)
 		}
-		fmt.Printf("Answer saved to: %s\n", outputFile)
-	} else {
+	} else if !streamed {
 		fmt.Println(answer)
 	}
 	logger.Info("Answer found", zap.String("answer", answer))
 }
+
+// openSession resumes the saved session or starts a new one for the question.
+// A new session gets the plan from the plan file if it is given.
+func openSession(store *session.Store, args []string) (*session.Session, error) {
+	if resumeSession != "" {
+		return store.Load(resumeSession)
+	}
+
+	question := strings.Join(args, " ")
+	var plan *models.Plan
+	if planFile != "" {
+		var err error
+		if plan, err = readPlanFile(planFile); err != nil {
+			return nil, err
+		}
+		if question == "" {
+			question = plan.SearchQuery
+		}
+	}
+
+	sess, err := store.Create(question)
+	if err != nil {
+		return nil, err
+	}
+	if plan != nil {
+		if err := sess.SetPlan(plan); err != nil {
+			return nil, err
+		}
+	}
+
+	return sess, nil
+}
+
+// reviewPlan lets the user review the session plan in the terminal
+// and saves the approved plan. The plan is built first if the session has none.
+func reviewPlan(ctx context.Context, llmClient llm.LLM, sess *session.Session) error {
+	plan := sess.Plan
+	if plan == nil {
+		fmt.Fprintln(os.Stderr, "Building search plan...")
+		var err error
+		if plan, err = llmClient.PlanSearch(ctx, sess.Question); err != nil {
+			return fmt.Errorf("failed to build search plan: %w", err)
+		}
+	}
+
+	reviewed, err := review.NewReviewer(llmClient, os.Stdin, os.Stdout, logger).Review(ctx, sess.Question, plan)
+	if err != nil {
+		return err
+	}
+
+	return sess.SetPlan(reviewed)
+}
+
+// newPrefilter creates the embedding relevance prefilter from the config,
+// nil if it is disabled. The OpenAI embedder uses the OpenAI endpoint settings
+// unless the prefilter sets its own base URL and API key.
+// Tokens of the embeddings are accounted in the meter.
+func newPrefilter(cfg *config.Config, meter *usage.Meter) (search.Prefilter, error) {
+	if !cfg.Relevance.Enabled {
+		return nil, nil
+	}
+
+	var embedder relevance.Embedder
+	switch cfg.Relevance.Provider {
+	case "openai":
+		endpoint := openai.Endpoint{
+			APIKey:       cfg.OpenAI.APIKey,
+			BaseURL:      cfg.OpenAI.BaseURL,
+			Organization: cfg.OpenAI.Organization,
+			Project:      cfg.OpenAI.Project,
+			Headers:      cfg.OpenAI.Headers,
+			Proxy:        cfg.OpenAI.Proxy,
+			Retry:        cfg.OpenAI.Retry.Policy(),
+		}
+		if cfg.Relevance.BaseURL != "" {
+			endpoint = openai.Endpoint{
+				APIKey:  cfg.Relevance.APIKey,
+				BaseURL: cfg.Relevance.BaseURL,
+				Proxy:   cfg.OpenAI.Proxy,
+				Retry:   cfg.OpenAI.Retry.Policy(),
+			}
+		} else if cfg.Relevance.APIKey != "" {
+			endpoint.APIKey = cfg.Relevance.APIKey
+		}
+		if endpoint.APIKey == "" && endpoint.BaseURL == "" {
+			return nil, fmt.Errorf("OpenAI API key not found")
+		}
+		var err error
+		if embedder, err = openai.NewEmbedder(endpoint, logger, cfg.Relevance.Model); err != nil {
+			return nil, err
+		}
+	case "local":
+		embedder = relevance.NewLocalEmbedder()
+	default:
+		return nil, fmt.Errorf("unknown embedding provider: %s", cfg.Relevance.Provider)
+	}
+
+	return relevance.NewService(usage.NewEmbedder(embedder, meter), logger,
+		cfg.Relevance.Threshold,
+		cfg.Relevance.TopK,
+		cfg.Relevance.ChunkTokens), nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/dimdasci/seek/internal/config"
+	"github.com/dimdasci/seek/internal/session"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	askSession string
+	askOutput  string
+)
+
+// askCmd represents the ask command
+var askCmd = &cobra.Command{
+	Use:   "ask [follow-up question]",
+	Short: "Ask a follow-up question on a previous answer",
+	Long: `Ask command answers a follow-up question of a completed session.
+The question is answered from the pages and findings of the session first,
+the web is searched only if they do not cover the question.
+The answer is appended to the session report and its references.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runAskCmd,
+}
+
+func init() {
+	rootCmd.AddCommand(askCmd)
+
+	askCmd.Flags().StringVar(&askSession, "session", "", "ID of the session to continue")
+	askCmd.Flags().StringVarP(&askOutput, "output", "o", "", "output file for the result in markdown format")
+	askCmd.Flags().StringVar(&progressFormat, "progress", progressText, "progress output to stderr: text, json or none")
+	askCmd.MarkFlagRequired("session")
+}
+
+func runAskCmd(cmd *cobra.Command, args []string) {
+	question := strings.Join(args, " ")
+	cfg := config.Get()
+
+	observer, stopProgress, err := newObserver(progressFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exitCode = exitFailure
+		return
+	}
+	defer stopProgress()
+
+	sess, err := session.NewStore(cfg.Session.Dir).Load(askSession)
+	if err != nil {
+		logger.Error("Failed to open session", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to open session: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+	logger.Info("Answering follow-up question",
+		zap.String("session", sess.ID),
+		zap.String("question", question))
+
+	meter := newMeter(cfg)
+	llmClient, err := newLLM(cfg, meter)
+	if err != nil {
+		logger.Error("Failed to create LLM client", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to create LLM client: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+	searchService, err := newSearchService(cfg, llmClient, newWebReader(cfg), false, observer, meter)
+	if err != nil {
+		logger.Error("Failed to create search service", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to create search service: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+
+	// Ctrl-C stops the run keeping the saved progress
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := searchService.FollowUp(ctx, sess, question)
+	stopProgress()
+	if err != nil {
+		logger.Error("Failed to answer follow-up question", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to answer follow-up question: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+
+	writeAnswer(result.Report, askOutput, false)
+	fmt.Fprint(os.Stderr, meter.Summary())
+	if result.Partial() {
+		exitCode = exitPartial
+	}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dimdasci/seek/internal/client/anthropic"
+	"github.com/dimdasci/seek/internal/client/openai"
+	"github.com/dimdasci/seek/internal/config"
+	"github.com/dimdasci/seek/internal/llm"
+	"github.com/dimdasci/seek/internal/llm/fake"
+	"github.com/dimdasci/seek/internal/usage"
+	"go.uber.org/zap"
+)
+
+// newLLM creates the LLM client for the provider selected in the config.
+// Tokens of the completions are accounted in the meter if it is not nil.
+func newLLM(cfg *config.Config, meter *usage.Meter) (llm.LLM, error) {
+	logger.Debug("Initializing LLM client", zap.String("provider", cfg.LLM.Provider))
+
+	switch cfg.LLM.Provider {
+	case "openai":
+		// local OpenAI-compatible servers may not need an API key
+		if cfg.OpenAI.APIKey == "" && cfg.OpenAI.BaseURL == "" {
+			return nil, fmt.Errorf("OpenAI API key not found")
+		}
+		models := make([]openai.Model, 0, len(cfg.OpenAI.Models))
+		for _, m := range cfg.OpenAI.Models {
+			models = append(models, openai.Model(m))
+		}
+		completer, err := openai.NewClient(
+			openai.Endpoint{
+				APIKey:       cfg.OpenAI.APIKey,
+				BaseURL:      cfg.OpenAI.BaseURL,
+				Organization: cfg.OpenAI.Organization,
+				Project:      cfg.OpenAI.Project,
+				Headers:      cfg.OpenAI.Headers,
+				Proxy:        cfg.OpenAI.Proxy,
+				Retry:        cfg.OpenAI.Retry.Policy(),
+			},
+			logger,
+			models,
+			cfg.OpenAI.Reasoning.Model,
+			cfg.OpenAI.Completion.Model,
+			cfg.OpenAI.Reasoning.MaxTokens,
+			cfg.OpenAI.Completion.MaxTokens,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OpenAI client: %w", err)
+		}
+		return llm.NewClient(usage.NewCompleter(completer, meter), logger,
+			cfg.OpenAI.Reasoning.Timeout,
+			cfg.OpenAI.Completion.Timeout), nil
+
+	case "anthropic":
+		if cfg.Anthropic.APIKey == "" {
+			return nil, fmt.Errorf("Anthropic API key not found")
+		}
+		completer, err := anthropic.NewClient(
+			cfg.Anthropic.APIKey,
+			cfg.Anthropic.BaseURL,
+			cfg.Anthropic.Retry.Policy(),
+			logger,
+			cfg.Anthropic.Reasoning.Model,
+			cfg.Anthropic.Completion.Model,
+			cfg.Anthropic.Reasoning.MaxTokens,
+			cfg.Anthropic.Completion.MaxTokens,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Anthropic client: %w", err)
+		}
+		return llm.NewClient(usage.NewCompleter(completer, meter), logger,
+			cfg.Anthropic.Reasoning.Timeout,
+			cfg.Anthropic.Completion.Timeout), nil
+
+	case "fake":
+		return fake.NewClient(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %s", cfg.LLM.Provider)
+	}
+}
+
+// newMeter creates the meter of a research run with the budget and the price table from the config.
+func newMeter(cfg *config.Config) *usage.Meter {
+	prices := make(map[string]usage.Price, len(usage.DefaultPrices)+len(cfg.Budget.Prices))
+	for model, price := range usage.DefaultPrices {
+		prices[model] = price
+	}
+	for _, p := range cfg.Budget.Prices {
+		prices[p.Model] = usage.Price{Input: p.Input, Output: p.Output}
+	}
+
+	return usage.NewMeter(usage.Limits{
+		MaxTokens: cfg.Budget.MaxTokens,
+		MaxCost:   cfg.Budget.MaxCost,
+		MaxPages:  cfg.Budget.MaxPages,
+		MaxTime:   cfg.Budget.MaxTime,
+	}, prices)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dimdasci/seek/internal/config"
+	"github.com/dimdasci/seek/internal/mcp"
+	"github.com/dimdasci/seek/internal/service/webread"
+	"github.com/dimdasci/seek/internal/service/websearch"
+	"github.com/dimdasci/seek/internal/session"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// mcpCmd represents the mcp command
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run seek as a Model Context Protocol server over stdio",
+	Long: `MCP command runs a Model Context Protocol server reading requests
+from stdin and writing responses to stdout. It exposes the tools:
+
+  web_search  search the web with the configured provider
+  read_urls   read web pages and convert them to markdown
+  research    answer a question with a full research run
+
+Research runs are saved as sessions and report their progress
+to the clients that ask for it.`,
+	Args: cobra.NoArgs,
+	Run:  runMCPCmd,
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+func runMCPCmd(cmd *cobra.Command, args []string) {
+	cfg := config.Get()
+
+	webSearcher, err := websearch.NewWebSearcher(logger, cfg.WebSearch.Provider, cfg.WebSearch.Retry.Policy())
+	if err != nil {
+		logger.Error("Failed to create web searcher", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to create web searcher: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+
+	// one reader, and so one headless browser, serves all the tool calls
+	readerFactory, err := webread.NewReaderFactory(logger, cfg.WebReader.Timeout, cfg.WebReader.MinContentLength, cfg.WebReader.Retry.Policy())
+	if err != nil {
+		logger.Error("Failed to initialize web reader", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to initialize web reader: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+	defer readerFactory.Close()
+	reader := readerFactory.GetReader()
+
+	version := Version
+	if version == "" {
+		version = "dev"
+	}
+	server := mcp.NewServer(logger, "seek", version,
+		mcp.NewWebSearchTool(webSearcher),
+		mcp.NewReadURLsTool(reader),
+		mcp.NewResearchTool(session.NewStore(cfg.Session.Dir), searchFactory(cfg, reader), cfg.MCP.MaxResearch, logger),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("Serving MCP over stdio")
+	if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil {
+		logger.Error("Failed to serve MCP", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to serve MCP: %v\n", err)
+		exitCode = exitFailure
+	}
+}
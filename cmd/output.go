@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dimdasci/seek/internal/progress"
+)
+
+// Formats of the progress output
+const (
+	progressText = "text" // Lines of text, with a spinner on a terminal
+	progressJSON = "json" // JSON lines
+	progressNone = "none" // No progress output
+)
+
+// progressFormat is the format of the progress output to stderr.
+var progressFormat string
+
+// Formats of the answer
+const (
+	formatMarkdown = "markdown" // Markdown report
+	formatJSON     = "json"     // JSON document of the research run
+)
+
+// outputFormat is the format of the answer.
+var outputFormat string
+
+// newObserver creates the progress renderer of the format writing to stderr.
+// It returns the observer, nil for no progress output, and the function stopping it.
+func newObserver(format string) (progress.Observer, func(), error) {
+	switch format {
+	case progressText:
+		text := progress.NewText(os.Stderr, isTerminal(os.Stderr))
+		return text, text.Close, nil
+	case progressJSON:
+		return progress.NewJSON(os.Stderr), func() {}, nil
+	case progressNone:
+		return nil, func() {}, nil
+	}
+	return nil, nil, fmt.Errorf("unknown progress format: %s", format)
+}
+
+// reportStream prints the report streamed by the search service
+// and keeps the printed text to complete the report at the end.
+// A nil stream streams nothing.
+type reportStream struct {
+	w       io.Writer
+	printed strings.Builder
+}
+
+// writer returns the writer the report is streamed to, nil for a nil stream.
+func (s *reportStream) writer() io.Writer {
+	if s == nil {
+		return nil
+	}
+	return s
+}
+
+// Write prints the streamed text.
+func (s *reportStream) Write(p []byte) (int, error) {
+	s.printed.Write(p)
+	return s.w.Write(p)
+}
+
+// streamed reports whether any text of the report has been printed.
+func (s *reportStream) streamed() bool {
+	return s != nil && s.printed.Len() > 0
+}
+
+// finish prints the rest of the final report after the streamed text,
+// such as the references and the claim verification.
+// The whole report is printed again if it does not continue the streamed text.
+func (s *reportStream) finish(report string) {
+	if !s.streamed() {
+		return
+	}
+	printed := s.printed.String()
+	body := strings.TrimRight(printed, "\n")
+	rest, ok := strings.CutPrefix(report, body)
+	if !ok {
+		rest = "\n\n" + report
+	}
+	// skip the line breaks already printed after the body
+	for range len(printed) - len(body) {
+		rest = strings.TrimPrefix(rest, "\n")
+	}
+	rest = strings.TrimRight(rest, "\n")
+	if rest != "" {
+		fmt.Fprintln(s.w, rest)
+	}
+}
+
+// isTerminal reports whether the file is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// writeFile writes the data to the file atomically: the data is written
+// to a temporary file in the same directory and renamed to the file.
+func writeFile(name string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dimdasci/seek/internal/config"
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	planFormat string
+	planOutput string
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan [question]",
+	Short: "Build a search plan for your question without executing it",
+	Long: `Plan command builds the search plan for the question and prints it
+as JSON or YAML. Edit the saved plan and run it with seek answer --plan.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runPlanCmd,
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+
+	planCmd.Flags().StringVarP(&planFormat, "format", "f", "json", "plan format: json or yaml")
+	planCmd.Flags().StringVarP(&planOutput, "output", "o", "", "output file for the plan")
+}
+
+func runPlanCmd(cmd *cobra.Command, args []string) {
+	question := strings.Join(args, " ")
+	logger.Info("Building search plan", zap.String("question", question))
+
+	if planFormat != "json" && planFormat != "yaml" {
+		fmt.Fprintf(os.Stderr, "Unknown plan format: %s\n", planFormat)
+		exitCode = exitFailure
+		return
+	}
+
+	cfg := config.Get()
+
+	llmClient, err := newLLM(cfg, nil)
+	if err != nil {
+		logger.Error("Failed to create LLM client", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to create LLM client: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+
+	plan, err := llmClient.PlanSearch(context.Background(), question)
+	if err != nil {
+		logger.Error("Failed to build search plan", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to build search plan: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+
+	var out string
+	switch planFormat {
+	case "yaml":
+		out = plan.YAML()
+	default:
+		out = plan.String() + "\n"
+	}
+
+	if planOutput != "" {
+		if err := writeFile(planOutput, []byte(out)); err != nil {
+			logger.Error("Failed to write to file", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Failed to write to file: %v\n", err)
+			exitCode = exitFailure
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Plan saved to: %s\n", planOutput)
+	} else {
+		fmt.Print(out)
+	}
+}
+
+// readPlanFile reads a search plan from the JSON or YAML file.
+// The format is chosen by the file extension, JSON by default.
+func readPlanFile(path string) (*models.Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var plan *models.Plan
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		plan, err = models.NewPlanFromYAML(string(data))
+	default:
+		plan, err = models.NewPlan(string(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan file %s: %w", path, err)
+	}
+
+	return plan, nil
+}
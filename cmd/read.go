@@ -39,7 +39,7 @@ func runReadCmd(cmd *cobra.Command, args []string) {
 	}
 
 	logger.Debug("Initializing web reader", zap.Duration("timeout", cfg.WebReader.Timeout), zap.Int("min_content_length", cfg.WebReader.MinContentLength))
-	readerFactory, err := webread.NewReaderFactory(logger, cfg.WebReader.Timeout, cfg.WebReader.MinContentLength)
+	readerFactory, err := webread.NewReaderFactory(logger, cfg.WebReader.Timeout, cfg.WebReader.MinContentLength, cfg.WebReader.Retry.Policy())
 	if err != nil {
 		logger.Error("Failed to initialize web reader", zap.Error(err))
 		fmt.Printf("Failed to initialize web reader: %v\n", err)
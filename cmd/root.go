@@ -12,7 +12,14 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// Exit codes of the commands.
+const (
+	exitFailure = 1 // Command failed
+	exitPartial = 2 // Answer is written without some of the research steps
+)
+
 var (
+	exitCode   int // Exit code set by the command
 	cfgFile    string
 	logger     *zap.Logger
 	Version    string
@@ -56,7 +63,10 @@ POSIX-compliant interface.`,
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitFailure)
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
 	}
 }
 
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dimdasci/seek/internal/config"
+	"github.com/dimdasci/seek/internal/server"
+	"github.com/dimdasci/seek/internal/service/webread"
+	"github.com/dimdasci/seek/internal/session"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// shutdownTimeout is the time the open connections get to finish on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+var serveAddress string
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run seek as an HTTP API server",
+	Long: `Serve command runs the HTTP API of seek:
+
+  POST /v1/plan             build the search plan for a question
+  POST /v1/answer           start an answer job, returns the job ID
+  GET  /v1/jobs/{id}        status of the job and its result
+  GET  /v1/jobs/{id}/events progress of the job as Server-Sent Events
+  POST /v1/read             read web pages and convert them to markdown
+
+Jobs are saved as sessions, a job stopped by shutdown can be resumed
+with seek answer --resume and the job ID.`,
+	Args: cobra.NoArgs,
+	Run:  runServeCmd,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddress, "address", "", "address to listen on, overrides server.address")
+}
+
+func runServeCmd(cmd *cobra.Command, args []string) {
+	cfg := config.Get()
+
+	address := cfg.Server.Address
+	if serveAddress != "" {
+		address = serveAddress
+	}
+
+	planner, err := newLLM(cfg, nil)
+	if err != nil {
+		logger.Error("Failed to create LLM client", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to create LLM client: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+
+	// one reader, and so one headless browser, serves all the requests
+	readerFactory, err := webread.NewReaderFactory(logger, cfg.WebReader.Timeout, cfg.WebReader.MinContentLength, cfg.WebReader.Retry.Policy())
+	if err != nil {
+		logger.Error("Failed to initialize web reader", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to initialize web reader: %v\n", err)
+		exitCode = exitFailure
+		return
+	}
+	defer readerFactory.Close()
+	reader := readerFactory.GetReader()
+
+	newSearch := searchFactory(cfg, reader)
+	apiServer := server.NewServer(planner, reader, session.NewStore(cfg.Session.Dir), newSearch, logger, server.Limits{
+		RequestTimeout: cfg.Server.RequestTimeout,
+		JobTimeout:     cfg.Server.JobTimeout,
+		MaxRequests:    cfg.Server.MaxRequests,
+		MaxReadURLs:    cfg.Server.MaxReadURLs,
+		MaxJobs:        cfg.Server.MaxJobs,
+		MaxQueued:      cfg.Server.MaxQueued,
+		JobTTL:         cfg.Server.JobTTL,
+	})
+	httpServer := &http.Server{
+		Addr:              address,
+		Handler:           apiServer.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+	logger.Info("Serving HTTP API", zap.String("address", address))
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", address)
+
+	select {
+	case err := <-serveErr:
+		logger.Error("Failed to serve HTTP API", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to serve HTTP API: %v\n", err)
+		exitCode = exitFailure
+		return
+	case <-ctx.Done():
+	}
+
+	fmt.Fprintln(os.Stderr, "Shutting down...")
+	// stopped jobs end their event streams before the connections are closed
+	apiServer.Shutdown()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Failed to shut down HTTP API", zap.Error(err))
+	}
+	logger.Info("HTTP API stopped")
+}
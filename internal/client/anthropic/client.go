@@ -0,0 +1,288 @@
+/* Package anthropic provides a client for the Anthropic Messages API. */
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/dimdasci/seek/internal/llm"
+	"github.com/dimdasci/seek/internal/retry"
+	"go.uber.org/zap"
+)
+
+const (
+	apiVersion     = "2023-06-01"                // Anthropic API version header value
+	defaultBaseURL = "https://api.anthropic.com" // Default API endpoint
+	contextWindow  = 200000                      // Context window of the Claude models in tokens
+)
+
+// Client is a client for the Anthropic Messages API.
+type Client struct {
+	apiKey              string       // Anthropic API key
+	baseURL             string       // API base URL
+	logger              *zap.Logger  // Logger
+	reasoningModel      string       // Model to use for reasoning
+	completionModel     string       // Model to use for completion
+	reasoningMaxTokens  int64        // Max tokens for reasoning
+	completionMaxTokens int64        // Max tokens for completion
+	httpClient          *http.Client // HTTP client for API requests
+}
+
+// NewClient creates a new Anthropic API client with apiKey, and logger.
+// Failed requests are retried by the policy.
+// It returns a pointer to the client.
+func NewClient(
+	apiKey string,
+	baseURL string,
+	policy retry.Policy,
+	logger *zap.Logger,
+	reasoningModel string,
+	completionModel string,
+	reasoningMaxTokens int64,
+	completionMaxTokens int64,
+) (*Client, error) {
+	if reasoningModel == "" || completionModel == "" {
+		return nil, fmt.Errorf("reasoning and completion models are required")
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Client{
+		apiKey:              apiKey,
+		baseURL:             strings.TrimRight(baseURL, "/"),
+		logger:              logger,
+		reasoningModel:      reasoningModel,
+		completionModel:     completionModel,
+		reasoningMaxTokens:  reasoningMaxTokens,
+		completionMaxTokens: completionMaxTokens,
+		httpClient:          retry.NewClient(policy, logger),
+	}, nil
+}
+
+// messageRequest represents the request body of the Messages API.
+type messageRequest struct {
+	Model       string      `json:"model"`
+	MaxTokens   int64       `json:"max_tokens"`
+	System      string      `json:"system,omitempty"`
+	Messages    []message   `json:"messages"`
+	Temperature *float64    `json:"temperature,omitempty"`
+	Tools       []tool      `json:"tools,omitempty"`
+	ToolChoice  *toolChoice `json:"tool_choice,omitempty"`
+	Stream      bool        `json:"stream,omitempty"`
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type tool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema interface{} `json:"input_schema"`
+}
+
+type toolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// messageResponse represents the response body of the Messages API.
+type messageResponse struct {
+	Model      string         `json:"model"`
+	StopReason string         `json:"stop_reason"`
+	Content    []contentBlock `json:"content"`
+	Usage      struct {
+		InputTokens  int64 `json:"input_tokens"`
+		OutputTokens int64 `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+type contentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// Complete sends the chat completion request to the Messages API.
+// Structured output is requested as a forced tool call with the schema as input.
+func (c *Client) Complete(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	model, maxTokens := c.completionModel, c.completionMaxTokens
+	if req.Tier == llm.TierReasoning {
+		model, maxTokens = c.reasoningModel, c.reasoningMaxTokens
+	}
+	if req.MaxTokens > 0 {
+		maxTokens = req.MaxTokens
+	}
+
+	body := messageRequest{
+		Model:       model,
+		MaxTokens:   maxTokens,
+		System:      req.System,
+		Messages:    []message{{Role: "user", Content: req.Prompt}},
+		Temperature: req.Temperature,
+	}
+	// the truncated output is prefilled as the assistant turn the model continues,
+	// the API rejects a prefill ending with whitespace
+	if partial := strings.TrimRight(req.Partial, " \t\r\n"); partial != "" {
+		body.Messages = append(body.Messages, message{Role: "assistant", Content: partial})
+	}
+	if req.Schema != nil {
+		body.Tools = []tool{{
+			Name:        req.Schema.Name,
+			Description: req.Schema.Description,
+			InputSchema: req.Schema.Schema,
+		}}
+		body.ToolChoice = &toolChoice{Type: "tool", Name: req.Schema.Name}
+	} else if req.Stream != nil {
+		body.Stream = true
+	}
+
+	requestBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v1/messages", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", c.apiKey)
+	httpReq.Header.Set("anthropic-version", apiVersion)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	c.logger.Debug("Response status", zap.Int("status", resp.StatusCode))
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var msg messageResponse
+	if body.Stream {
+		if err := readStream(resp.Body, &msg, req.Stream); err != nil {
+			return nil, fmt.Errorf("failed to read response stream: %w", err)
+		}
+	} else if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	var content strings.Builder
+	for _, block := range msg.Content {
+		switch block.Type {
+		case "text":
+			if req.Schema == nil {
+				content.WriteString(block.Text)
+			}
+		case "tool_use":
+			content.Write(block.Input)
+		}
+	}
+
+	return &llm.Response{
+		Content:          content.String(),
+		FinishReason:     finishReason(msg.StopReason),
+		Model:            msg.Model,
+		PromptTokens:     msg.Usage.InputTokens,
+		CompletionTokens: msg.Usage.OutputTokens,
+		MaxTokens:        maxTokens,
+	}, nil
+}
+
+// PromptLimit returns the prompt tokens that fit the context window of the tier
+// model next to the completion, 0 for models other than Claude.
+func (c *Client) PromptLimit(tier llm.Tier) int64 {
+	model, maxTokens := c.completionModel, c.completionMaxTokens
+	if tier == llm.TierReasoning {
+		model, maxTokens = c.reasoningModel, c.reasoningMaxTokens
+	}
+	if !strings.HasPrefix(model, "claude-") {
+		return 0
+	}
+	return max(contextWindow-maxTokens, 0)
+}
+
+// streamEvent represents a server-sent event of a streamed message.
+type streamEvent struct {
+	Type    string          `json:"type"`
+	Message messageResponse `json:"message"`
+	Delta   struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int64 `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// readStream reads the server-sent events of a streamed message into msg
+// and passes the text deltas to the callback as they arrive.
+func readStream(body io.Reader, msg *messageResponse, callback func(text string)) error {
+	var text strings.Builder
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+
+		var event streamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			*msg = event.Message
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" {
+				text.WriteString(event.Delta.Text)
+				callback(event.Delta.Text)
+			}
+		case "message_delta":
+			msg.StopReason = event.Delta.StopReason
+			msg.Usage.OutputTokens = event.Usage.OutputTokens
+		case "error":
+			return fmt.Errorf("%s: %s", event.Error.Type, event.Error.Message)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	msg.Content = []contentBlock{{Type: "text", Text: text.String()}}
+	return nil
+}
+
+// finishReason maps the Messages API stop reason to the chat completion finish reason.
+func finishReason(stopReason string) string {
+	switch stopReason {
+	case "max_tokens":
+		return llm.FinishReasonLength
+	case "refusal":
+		return llm.FinishReasonContentFilter
+	case "tool_use", "end_turn", "stop_sequence":
+		return "stop"
+	default:
+		return stopReason
+	}
+}
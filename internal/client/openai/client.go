@@ -2,9 +2,15 @@
 package openai
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"time"
+	"net/http"
+	"net/url"
+	"strings"
 
+	"github.com/dimdasci/seek/internal/llm"
+	"github.com/dimdasci/seek/internal/retry"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
 	"go.uber.org/zap"
@@ -12,90 +18,291 @@ import (
 
 // Client is a client for the OpenAI API.
 type Client struct {
-	client              *openai.Client   // OpenAI API client
-	logger              *zap.Logger      // Logger
-	reasoningModel      openai.ChatModel // Model to use for reasoning
-	completionModel     openai.ChatModel // Model to use for completion
-	reasoningTimeout    time.Duration    // Timeout for reasoning
-	completionTimeout   time.Duration    // Timeout for completion
-	reasoningMaxTokens  int64            // Max tokens for reasoning
-	completionMaxTokens int64            // Max tokens for completion
-
-	analysisResultSchemaParam    openai.ResponseFormatJSONSchemaJSONSchemaParam // Schema for analysis result
-	compilationResultSchemaParam openai.ResponseFormatJSONSchemaJSONSchemaParam // Schema for compilation result
+	client              *openai.Client // OpenAI API client
+	logger              *zap.Logger    // Logger
+	reasoningModel      Model          // Model to use for reasoning
+	completionModel     Model          // Model to use for completion
+	reasoningMaxTokens  int64          // Max tokens for reasoning
+	completionMaxTokens int64          // Max tokens for completion
 }
 
-// NewClient creates a new OpenAI API client with apiKey, and logger.
+// Endpoint holds the settings to connect to the OpenAI API or a compatible server.
+type Endpoint struct {
+	APIKey       string            // API key, optional for servers without authentication
+	BaseURL      string            // Base URL of the API, empty for api.openai.com
+	Organization string            // OpenAI organization ID
+	Project      string            // OpenAI project ID
+	Headers      map[string]string // Extra headers sent with every request
+	Proxy        string            // HTTP proxy URL
+	Retry        retry.Policy      // Retries of the failed requests
+}
+
+// NewClient creates a new OpenAI API client with endpoint, and logger.
+// Model names are resolved with the default models extended by models.
 // It returns a pointer to the client.
 func NewClient(
-	apiKey string,
+	endpoint Endpoint,
 	logger *zap.Logger,
-	reasoningModel openai.ChatModel,
-	completionModel openai.ChatModel,
-	reasoningTimeout time.Duration,
-	completionTimeout time.Duration,
+	models []Model,
+	reasoningModel string,
+	completionModel string,
 	reasoningMaxTokens int64,
 	completionMaxTokens int64,
 ) (*Client, error) {
-	client := openai.NewClient(
-		option.WithAPIKey(apiKey),
-	)
+	opts, err := endpoint.options(logger)
+	if err != nil {
+		logger.Error("failed to configure OpenAI endpoint",
+			zap.Error(err),
+			zap.String("base_url", endpoint.BaseURL))
+		return nil, err
+	}
+	client := openai.NewClient(opts...)
 
-	rm, err := model(reasoningModel)
+	known := newRegistry(models)
+
+	rm, err := known.model(reasoningModel)
 	if err != nil {
-		logger.Fatal("failed to create reasoning model",
+		logger.Error("failed to create reasoning model",
 			zap.Error(err),
 			zap.String("requested model", reasoningModel))
 		return nil, err
 	}
 
-	sm, err := model(completionModel)
+	sm, err := known.model(completionModel)
 	if err != nil {
-		logger.Fatal("failed to create service model",
+		logger.Error("failed to create service model",
 			zap.Error(err),
 			zap.String("requested model", completionModel))
 		return nil, err
 	}
 
-	analysisResultSchemaParam := openai.ResponseFormatJSONSchemaJSONSchemaParam{
-		Name:        openai.F("PageAnalysis"), // Updated to match the required pattern
-		Description: openai.F("Relevance and key points from the page"),
-		Schema:      openai.F(GenerateSchema[AnalysisResult]()),
-		Strict:      openai.Bool(true),
+	return &Client{
+		client:              client,
+		logger:              logger,
+		reasoningModel:      rm,
+		completionModel:     sm,
+		reasoningMaxTokens:  reasoningMaxTokens,
+		completionMaxTokens: completionMaxTokens,
+	}, nil
+}
+
+// options returns the request options to connect to the endpoint.
+// Failed requests are retried by the endpoint policy instead of the SDK.
+func (e Endpoint) options(logger *zap.Logger) ([]option.RequestOption, error) {
+	var opts []option.RequestOption
+
+	if e.APIKey != "" {
+		opts = append(opts, option.WithAPIKey(e.APIKey))
+	}
+	if e.BaseURL != "" {
+		u, err := url.Parse(e.BaseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid base URL: %s", e.BaseURL)
+		}
+		// paths are resolved relative to the base URL
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		opts = append(opts, option.WithBaseURL(u.String()))
+	}
+	if e.Organization != "" {
+		opts = append(opts, option.WithOrganization(e.Organization))
+	}
+	if e.Project != "" {
+		opts = append(opts, option.WithProject(e.Project))
+	}
+	for key, value := range e.Headers {
+		opts = append(opts, option.WithHeader(key, value))
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if e.Proxy != "" {
+		proxy, err := url.Parse(e.Proxy)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL: %s", e.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	opts = append(opts,
+		option.WithHTTPClient(&http.Client{Transport: retry.NewTransport(transport, e.Retry, logger)}),
+		option.WithMaxRetries(0))
+
+	return opts, nil
+}
+
+// continuePrompt asks the model to continue its truncated output.
+const continuePrompt = "Your answer was cut off. Continue it exactly where it stops, " +
+	"without repeating any of the text already written or adding any comments."
+
+// Complete sends the chat completion request to the OpenAI API.
+// The request is adjusted to the features supported by the model.
+func (c *Client) Complete(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	model, maxTokens := c.completionModel, c.completionMaxTokens
+	if req.Tier == llm.TierReasoning {
+		model, maxTokens = c.reasoningModel, c.reasoningMaxTokens
 	}
-	compilationResultSchemaParam := openai.ResponseFormatJSONSchemaJSONSchemaParam{
-		Name:        openai.F("PageAnalysis"), // Updated to match the required pattern
-		Description: openai.F("Relevance and key points from the page"),
-		Schema:      openai.F(GenerateSchema[CompilationResult]()),
-		Strict:      openai.Bool(true),
+	if req.MaxTokens > 0 {
+		maxTokens = req.MaxTokens
 	}
 
-	return &Client{
-		client:                       client,
-		logger:                       logger,
-		reasoningModel:               rm,
-		completionModel:              sm,
-		reasoningTimeout:             reasoningTimeout,
-		completionTimeout:            completionTimeout,
-		reasoningMaxTokens:           reasoningMaxTokens,
-		completionMaxTokens:          completionMaxTokens,
-		analysisResultSchemaParam:    analysisResultSchemaParam,
-		compilationResultSchemaParam: compilationResultSchemaParam,
+	system, prompt := req.System, req.Prompt
+
+	// emulate structured output with instructions for models without json_schema
+	emulateSchema := req.Schema != nil && !model.JSONSchema
+	if emulateSchema {
+		schema, err := json.Marshal(req.Schema.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schema: %w", err)
+		}
+		prompt = fmt.Sprintf("%s\n\n"+
+			"Respond with a single JSON object matching the JSON schema below. "+
+			"Do not write anything else.\n\n<json_schema>%s<json_schema>",
+			prompt, schema)
+	}
+
+	// fold the system prompt into the user message for models without system messages
+	if system != "" && !model.SystemMessages {
+		prompt = fmt.Sprintf("%s\n\n%s", system, prompt)
+		system = ""
+	}
+
+	// fit the completion into the model limits
+	if model.MaxOutputTokens > 0 && maxTokens > model.MaxOutputTokens {
+		maxTokens = model.MaxOutputTokens
+	}
+	if model.ContextWindow > 0 {
+		available := model.ContextWindow - llm.EstimateTokens(system+prompt+req.Partial)
+		if available <= 0 {
+			return nil, fmt.Errorf("prompt exceeds the context window of %s: %d tokens",
+				model.Name, model.ContextWindow)
+		}
+		if maxTokens > available {
+			c.logger.Warn("Max tokens reduced to fit the context window",
+				zap.String("model", model.Name),
+				zap.Int64("max tokens", maxTokens),
+				zap.Int64("available tokens", available))
+			maxTokens = available
+		}
+	}
+
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, 4)
+	if system != "" {
+		messages = append(messages, openai.SystemMessage(system))
+	}
+	messages = append(messages, openai.UserMessage(prompt))
+
+	// ask the model to continue the truncated output
+	if req.Partial != "" {
+		messages = append(messages,
+			openai.AssistantMessage(req.Partial),
+			openai.UserMessage(continuePrompt))
+	}
+
+	params := openai.ChatCompletionNewParams{
+		Messages:            openai.F(messages),
+		Model:               openai.F(model.ID),
+		MaxCompletionTokens: openai.Int(maxTokens),
+	}
+	if req.Temperature != nil && model.Temperature {
+		params.Temperature = openai.Float(*req.Temperature)
+	}
+	if req.Schema != nil && model.JSONSchema {
+		params.ResponseFormat = openai.F[openai.ChatCompletionNewParamsResponseFormatUnion](
+			openai.ResponseFormatJSONSchemaParam{
+				Type: openai.F(openai.ResponseFormatJSONSchemaTypeJSONSchema),
+				JSONSchema: openai.F(openai.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:        openai.F(req.Schema.Name),
+					Description: openai.F(req.Schema.Description),
+					Schema:      openai.F(req.Schema.Schema),
+					Strict:      openai.Bool(true),
+				}),
+			},
+		)
+	}
+
+	var chat *openai.ChatCompletion
+	var err error
+	if req.Stream != nil && req.Schema == nil {
+		chat, err = c.stream(ctx, params, req.Stream)
+	} else {
+		chat, err = c.client.Chat.Completions.New(ctx, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(chat.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in completion")
+	}
+
+	content := chat.Choices[0].Message.Content
+	if emulateSchema {
+		content = extractJSON(content)
+	}
+
+	return &llm.Response{
+		Content:          content,
+		Refusal:          chat.Choices[0].Message.Refusal,
+		FinishReason:     string(chat.Choices[0].FinishReason),
+		Model:            chat.Model,
+		PromptTokens:     chat.Usage.PromptTokens,
+		CompletionTokens: chat.Usage.CompletionTokens,
+		MaxTokens:        maxTokens,
 	}, nil
 }
 
-func model(m string) (openai.ChatModel, error) {
-	switch m {
-	case "gpt4", "gpt4o-mini":
-		return openai.ChatModelGPT4oMini, nil
-	case "o1-preview":
-		return openai.ChatModelO1Preview, nil
-	case "o1-mini":
-		return openai.ChatModelO1Mini, nil
-	case "gpt4o":
-		return openai.ChatModelGPT4o, nil
+// PromptLimit returns the prompt tokens that fit the context window of the tier
+// model next to the completion, 0 if the context window is unknown.
+func (c *Client) PromptLimit(tier llm.Tier) int64 {
+	model, maxTokens := c.completionModel, c.completionMaxTokens
+	if tier == llm.TierReasoning {
+		model, maxTokens = c.reasoningModel, c.reasoningMaxTokens
+	}
+	if model.ContextWindow == 0 {
+		return 0
+	}
+	if model.MaxOutputTokens > 0 && maxTokens > model.MaxOutputTokens {
+		maxTokens = model.MaxOutputTokens
+	}
+	return max(model.ContextWindow-maxTokens, 0)
+}
+
+// extractJSON returns the JSON object from a completion
+// that may wrap it into a markdown code block or surrounding text.
+func extractJSON(content string) string {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return content
+	}
+	return content[start : end+1]
+}
+
+// stream sends the chat completion request with streaming and passes the text
+// to the callback as it is generated. It returns the accumulated completion.
+func (c *Client) stream(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+	callback func(text string),
+) (*openai.ChatCompletion, error) {
+	params.StreamOptions = openai.F(openai.ChatCompletionStreamOptionsParam{
+		IncludeUsage: openai.F(true),
+	})
+
+	stream := c.client.Chat.Completions.NewStreaming(ctx, params)
+	defer stream.Close()
 
-	default:
-		return "", fmt.Errorf("unknown model: %s", m)
+	acc := openai.ChatCompletionAccumulator{}
+	for stream.Next() {
+		chunk := stream.Current()
+		if !acc.AddChunk(chunk) {
+			return nil, fmt.Errorf("failed to accumulate completion chunk")
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			callback(chunk.Choices[0].Delta.Content)
+		}
 	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+
+	return &acc.ChatCompletion, nil
 }
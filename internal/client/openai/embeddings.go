@@ -0,0 +1,82 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dimdasci/seek/internal/llm"
+	"github.com/openai/openai-go"
+	"go.uber.org/zap"
+)
+
+// embeddingBatchSize is the max number of texts embedded in a single request.
+const embeddingBatchSize = 256
+
+// Embedder is a client for the OpenAI embeddings API or a compatible server.
+type Embedder struct {
+	client *openai.Client // OpenAI API client
+	logger *zap.Logger    // Logger
+	model  string         // Embedding model
+}
+
+// NewEmbedder creates a new embeddings client with endpoint, logger and model.
+// It returns a pointer to the client.
+func NewEmbedder(endpoint Endpoint, logger *zap.Logger, model string) (*Embedder, error) {
+	if model == "" {
+		return nil, fmt.Errorf("embedding model is required")
+	}
+	opts, err := endpoint.options(logger)
+	if err != nil {
+		logger.Error("failed to configure OpenAI endpoint",
+			zap.Error(err),
+			zap.String("base_url", endpoint.BaseURL))
+		return nil, err
+	}
+
+	return &Embedder{
+		client: openai.NewClient(opts...),
+		logger: logger,
+		model:  model,
+	}, nil
+}
+
+// Embed returns the embedding vectors of the texts in the texts order
+// with the tokens of all the batches.
+func (e *Embedder) Embed(ctx context.Context, texts []string) (*llm.Embeddings, error) {
+	vectors := make([][]float64, len(texts))
+	embeddings := &llm.Embeddings{Vectors: vectors, Model: e.model}
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(texts))
+
+		resp, err := e.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+			Input: openai.F[openai.EmbeddingNewParamsInputUnion](
+				openai.EmbeddingNewParamsInputArrayOfStrings(texts[start:end])),
+			Model: openai.F(e.model),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		e.logger.Debug("Embeddings",
+			zap.String("model", resp.Model),
+			zap.Int("texts", end-start),
+			zap.Int64("tokens", resp.Usage.PromptTokens))
+		embeddings.Model = resp.Model
+		embeddings.Tokens += resp.Usage.PromptTokens
+
+		for _, embedding := range resp.Data {
+			i := start + int(embedding.Index)
+			if i < start || i >= end {
+				return nil, fmt.Errorf("embedding index out of range: %d", embedding.Index)
+			}
+			vectors[i] = embedding.Embedding
+		}
+	}
+
+	for i, vector := range vectors {
+		if vector == nil {
+			return nil, fmt.Errorf("no embedding for text %d", i)
+		}
+	}
+	return embeddings, nil
+}
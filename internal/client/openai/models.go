@@ -0,0 +1,60 @@
+package openai
+
+import "fmt"
+
+// Model describes a chat model and the request features it supports.
+type Model struct {
+	Name            string // Name of the model used in the config
+	ID              string // Model identifier sent to the API
+	SystemMessages  bool   // Model accepts system messages
+	Temperature     bool   // Model accepts the temperature parameter
+	JSONSchema      bool   // Model supports json_schema response format
+	ContextWindow   int64  // Context window in tokens, 0 if unknown
+	MaxOutputTokens int64  // Max output tokens, 0 if unknown
+}
+
+// defaultModels are the models known without any configuration.
+// The legacy names gpt4, gpt4o-mini and gpt4o are kept for existing configs.
+var defaultModels = []Model{
+	{Name: "gpt-4o-mini", SystemMessages: true, Temperature: true, JSONSchema: true, ContextWindow: 128000, MaxOutputTokens: 16384},
+	{Name: "gpt-4o", SystemMessages: true, Temperature: true, JSONSchema: true, ContextWindow: 128000, MaxOutputTokens: 16384},
+	{Name: "o1", SystemMessages: true, Temperature: false, JSONSchema: true, ContextWindow: 200000, MaxOutputTokens: 100000},
+	{Name: "o1-mini", SystemMessages: false, Temperature: false, JSONSchema: false, ContextWindow: 128000, MaxOutputTokens: 65536},
+	{Name: "o1-preview", SystemMessages: false, Temperature: false, JSONSchema: false, ContextWindow: 128000, MaxOutputTokens: 32768},
+	{Name: "o3-mini", SystemMessages: true, Temperature: false, JSONSchema: true, ContextWindow: 200000, MaxOutputTokens: 100000},
+	{Name: "gpt4", ID: "gpt-4o-mini", SystemMessages: true, Temperature: true, JSONSchema: true, ContextWindow: 128000, MaxOutputTokens: 16384},
+	{Name: "gpt4o-mini", ID: "gpt-4o-mini", SystemMessages: true, Temperature: true, JSONSchema: true, ContextWindow: 128000, MaxOutputTokens: 16384},
+	{Name: "gpt4o", ID: "gpt-4o", SystemMessages: true, Temperature: true, JSONSchema: true, ContextWindow: 128000, MaxOutputTokens: 16384},
+}
+
+// registry resolves model names to model descriptions.
+type registry map[string]Model
+
+// newRegistry creates a registry of the default models extended by the given ones.
+// Models with the same name as a default model replace it.
+func newRegistry(models []Model) registry {
+	r := make(registry, len(defaultModels)+len(models))
+	for _, m := range defaultModels {
+		r.add(m)
+	}
+	for _, m := range models {
+		r.add(m)
+	}
+	return r
+}
+
+func (r registry) add(m Model) {
+	if m.ID == "" {
+		m.ID = m.Name
+	}
+	r[m.Name] = m
+}
+
+// model returns the model registered with the name.
+func (r registry) model(name string) (Model, error) {
+	m, ok := r[name]
+	if !ok {
+		return Model{}, fmt.Errorf("unknown model: %s", name)
+	}
+	return m, nil
+}
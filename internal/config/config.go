@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dimdasci/seek/internal/retry"
 	"github.com/spf13/viper"
 )
 
@@ -14,24 +15,121 @@ type Config struct {
 		Level string `yaml:"level"`
 		File  string `yaml:"file"`
 	} `yaml:"logging"`
+	LLM struct {
+		Provider string `yaml:"provider"`
+	} `yaml:"llm"`
 	OpenAI struct {
+		APIKey       string            `yaml:"api_key"`
+		BaseURL      string            `yaml:"base_url"`
+		Organization string            `yaml:"organization"`
+		Project      string            `yaml:"project"`
+		Headers      map[string]string `yaml:"headers"`
+		Proxy        string            `yaml:"proxy"`
+		Retry        RetryConfig       `yaml:"retry"`
+		Reasoning    ServiceConfig     `yaml:"reasoning"`
+		Completion   ServiceConfig     `yaml:"completion"`
+		Models       []ModelConfig     `yaml:"models"`
+	} `yaml:"openai"`
+	Anthropic struct {
 		APIKey     string        `yaml:"api_key"`
+		BaseURL    string        `yaml:"base_url"`
+		Retry      RetryConfig   `yaml:"retry"`
 		Reasoning  ServiceConfig `yaml:"reasoning"`
 		Completion ServiceConfig `yaml:"completion"`
-	} `yaml:"openai"`
+	} `yaml:"anthropic"`
 	WebSearch struct {
-		Tavily struct {
+		Provider string      `yaml:"provider"`
+		Retry    RetryConfig `yaml:"retry"`
+		Tavily   struct {
 			Timeout    time.Duration `yaml:"timeout"`
 			APIKey     string        `yaml:"api_key"`
 			SearchURL  string        `yaml:"search_url"`
 			ExtractURL string        `yaml:"extract_url"`
 			MaxResults int           `yaml:"max_results"`
 		} `yaml:"tavily"`
+		SearXNG struct {
+			Timeout    time.Duration `yaml:"timeout"`
+			URL        string        `yaml:"url"`
+			Categories string        `yaml:"categories"`
+			Language   string        `yaml:"language"`
+			MaxResults int           `yaml:"max_results"`
+		} `yaml:"searxng"`
+		Brave struct {
+			Timeout    time.Duration `yaml:"timeout"`
+			APIKey     string        `yaml:"api_key"`
+			SearchURL  string        `yaml:"search_url"`
+			MaxResults int           `yaml:"max_results"`
+		} `yaml:"brave"`
+		Bing struct {
+			Timeout    time.Duration `yaml:"timeout"`
+			APIKey     string        `yaml:"api_key"`
+			SearchURL  string        `yaml:"search_url"`
+			Market     string        `yaml:"market"`
+			MaxResults int           `yaml:"max_results"`
+		} `yaml:"bing"`
+		Google struct {
+			Timeout    time.Duration `yaml:"timeout"`
+			APIKey     string        `yaml:"api_key"`
+			CX         string        `yaml:"cx"`
+			SearchURL  string        `yaml:"search_url"`
+			MaxResults int           `yaml:"max_results"`
+		} `yaml:"google"`
+		DuckDuckGo struct {
+			Timeout    time.Duration `yaml:"timeout"`
+			SearchURL  string        `yaml:"search_url"`
+			Region     string        `yaml:"region"`
+			MaxResults int           `yaml:"max_results"`
+		} `yaml:"duckduckgo"`
+		Fusion struct {
+			Providers  []string `yaml:"providers"`
+			MaxResults int      `yaml:"max_results"`
+		} `yaml:"fusion"`
 	} `yaml:"websearch"`
 	WebReader struct {
 		Timeout          time.Duration `yaml:"timeout"`
 		MinContentLength int           `yaml:"min_content_length"`
+		Retry            RetryConfig   `yaml:"retry"`
 	} `yaml:"webreader"`
+	Search struct {
+		Concurrency int `yaml:"concurrency"`
+		Deepening   struct {
+			MaxDepth   int `yaml:"max_depth"`
+			MaxQueries int `yaml:"max_queries"`
+		} `yaml:"deepening"`
+	} `yaml:"search"`
+	Relevance struct {
+		Enabled     bool    `yaml:"enabled"`
+		Provider    string  `yaml:"provider"`
+		Model       string  `yaml:"model"`
+		BaseURL     string  `yaml:"base_url"`
+		APIKey      string  `yaml:"api_key"`
+		Threshold   float64 `yaml:"threshold"`
+		TopK        int     `yaml:"top_k"`
+		ChunkTokens int64   `yaml:"chunk_tokens"`
+	} `yaml:"relevance"`
+	Session struct {
+		Dir string `yaml:"dir"`
+	} `yaml:"session"`
+	Server struct {
+		Address        string        `yaml:"address"`
+		RequestTimeout time.Duration `yaml:"request_timeout"`
+		JobTimeout     time.Duration `yaml:"job_timeout"`
+		MaxRequests    int           `yaml:"max_requests"`
+		MaxReadURLs    int           `yaml:"max_read_urls"`
+		MaxJobs        int           `yaml:"max_jobs"`
+		MaxQueued      int           `yaml:"max_queued"`
+		JobTTL         time.Duration `yaml:"job_ttl"`
+	} `yaml:"server"`
+	MCP struct {
+		MaxResearch int `yaml:"max_research"`
+	} `yaml:"mcp"`
+	Budget struct {
+		MaxTokens int64         `yaml:"max_tokens"`
+		MaxCost   float64       `yaml:"max_cost"`
+		MaxPages  int           `yaml:"max_pages"`
+		MaxTime   time.Duration `yaml:"max_time"`
+		Prices    []PriceConfig `yaml:"prices"`
+	} `yaml:"budget"`
 }
 
 type ServiceConfig struct {
@@ -40,6 +138,49 @@ type ServiceConfig struct {
 	MaxTokens int64         `yaml:"max_tokens"`
 }
 
+// RetryConfig sets the retries of the failed requests to a service.
+type RetryConfig struct {
+	MaxAttempts  int           `yaml:"max_attempts"`
+	InitialDelay time.Duration `yaml:"initial_delay"`
+	MaxDelay     time.Duration `yaml:"max_delay"`
+}
+
+// Policy returns the retry policy of the config.
+func (r RetryConfig) Policy() retry.Policy {
+	return retry.Policy{
+		MaxAttempts:  r.MaxAttempts,
+		InitialDelay: r.InitialDelay,
+		MaxDelay:     r.MaxDelay,
+	}
+}
+
+// getRetry returns the retry config from the section with the key.
+func getRetry(key string) RetryConfig {
+	return RetryConfig{
+		MaxAttempts:  viper.GetInt(key + ".max_attempts"),
+		InitialDelay: viper.GetDuration(key + ".initial_delay"),
+		MaxDelay:     viper.GetDuration(key + ".max_delay"),
+	}
+}
+
+// ModelConfig declares a chat model and the request features it supports.
+type ModelConfig struct {
+	Name            string `yaml:"name" mapstructure:"name"`
+	ID              string `yaml:"id" mapstructure:"id"`
+	SystemMessages  bool   `yaml:"system_messages" mapstructure:"system_messages"`
+	Temperature     bool   `yaml:"temperature" mapstructure:"temperature"`
+	JSONSchema      bool   `yaml:"json_schema" mapstructure:"json_schema"`
+	ContextWindow   int64  `yaml:"context_window" mapstructure:"context_window"`
+	MaxOutputTokens int64  `yaml:"max_output_tokens" mapstructure:"max_output_tokens"`
+}
+
+// PriceConfig sets the price of a model in USD per million tokens.
+type PriceConfig struct {
+	Model  string  `yaml:"model" mapstructure:"model"`
+	Input  float64 `yaml:"input" mapstructure:"input"`
+	Output float64 `yaml:"output" mapstructure:"output"`
+}
+
 var appConfig Config
 
 func Load(cfgFile string) error {
@@ -65,9 +206,7 @@ func Load(cfgFile string) error {
 		return err
 	}
 
-	setValues()
-
-	return nil
+	return setValues()
 }
 
 func setDefaults() {
@@ -76,27 +215,101 @@ func setDefaults() {
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.file", filepath.Join(home, "logs", "seek.log"))
 
+	viper.SetDefault("llm.provider", "openai")
+
+	for _, service := range []string{"openai", "anthropic", "websearch", "webreader"} {
+		viper.SetDefault(service+".retry.max_attempts", 3)
+		viper.SetDefault(service+".retry.initial_delay", "1s")
+		viper.SetDefault(service+".retry.max_delay", "30s")
+	}
+
 	viper.SetDefault("openai.reasoning.timeout", "60s")
 	viper.SetDefault("openai.completion.timeout", "30s")
 	viper.SetDefault("openai.reasoning.max_tokens", 2000)
 	viper.SetDefault("openai.completion.max_tokens", 1000)
 
+	viper.SetDefault("anthropic.base_url", "https://api.anthropic.com")
+	viper.SetDefault("anthropic.reasoning.timeout", "60s")
+	viper.SetDefault("anthropic.completion.timeout", "30s")
+	viper.SetDefault("anthropic.reasoning.max_tokens", 2000)
+	viper.SetDefault("anthropic.completion.max_tokens", 1000)
+
+	viper.SetDefault("websearch.provider", "tavily")
 	viper.SetDefault("websearch.tavily.timeout", "10s")
+	viper.SetDefault("websearch.searxng.timeout", "10s")
+	viper.SetDefault("websearch.searxng.max_results", 10)
+	viper.SetDefault("websearch.brave.timeout", "10s")
+	viper.SetDefault("websearch.brave.search_url", "https://api.search.brave.com/res/v1/web/search")
+	viper.SetDefault("websearch.brave.max_results", 10)
+	viper.SetDefault("websearch.bing.timeout", "10s")
+	viper.SetDefault("websearch.bing.search_url", "https://api.bing.microsoft.com/v7.0/search")
+	viper.SetDefault("websearch.bing.max_results", 10)
+	viper.SetDefault("websearch.google.timeout", "10s")
+	viper.SetDefault("websearch.google.search_url", "https://www.googleapis.com/customsearch/v1")
+	viper.SetDefault("websearch.google.max_results", 10)
+	viper.SetDefault("websearch.duckduckgo.timeout", "10s")
+	viper.SetDefault("websearch.duckduckgo.search_url", "https://html.duckduckgo.com/html/")
+	viper.SetDefault("websearch.duckduckgo.max_results", 10)
+	viper.SetDefault("websearch.fusion.max_results", 10)
 	viper.SetDefault("webreader.timeout", "10s")
 	viper.SetDefault("webreader.min_content_length", 128)
+
+	viper.SetDefault("search.concurrency", 3)
+	viper.SetDefault("search.deepening.max_depth", 0)
+	viper.SetDefault("search.deepening.max_queries", 4)
+
+	viper.SetDefault("relevance.provider", "openai")
+	viper.SetDefault("relevance.model", "text-embedding-3-small")
+	viper.SetDefault("relevance.threshold", 0.2)
+	viper.SetDefault("relevance.top_k", 5)
+	viper.SetDefault("relevance.chunk_tokens", 512)
+
+	viper.SetDefault("session.dir", filepath.Join(home, ".seek", "sessions"))
+
+	viper.SetDefault("server.address", "127.0.0.1:8080")
+	viper.SetDefault("server.request_timeout", "2m")
+	viper.SetDefault("server.job_timeout", "30m")
+	viper.SetDefault("server.max_requests", 8)
+	viper.SetDefault("server.max_read_urls", 20)
+	viper.SetDefault("server.max_jobs", 2)
+	viper.SetDefault("server.max_queued", 20)
+	viper.SetDefault("server.job_ttl", "1h")
+
+	viper.SetDefault("mcp.max_research", 2)
 }
 
-func setValues() {
+func setValues() error {
 	appConfig.Logging.Level = viper.GetString("logging.level")
 	appConfig.Logging.File = viper.GetString("logging.file")
 
+	appConfig.LLM.Provider = viper.GetString("llm.provider")
+
 	appConfig.OpenAI.APIKey = viper.GetString("openai.api_key")
+	appConfig.OpenAI.BaseURL = viper.GetString("openai.base_url")
+	appConfig.OpenAI.Organization = viper.GetString("openai.organization")
+	appConfig.OpenAI.Project = viper.GetString("openai.project")
+	appConfig.OpenAI.Headers = viper.GetStringMapString("openai.headers")
+	appConfig.OpenAI.Proxy = viper.GetString("openai.proxy")
+	appConfig.OpenAI.Retry = getRetry("openai.retry")
 	appConfig.OpenAI.Reasoning.Model = viper.GetString("openai.reasoning.model")
 	appConfig.OpenAI.Reasoning.Timeout = viper.GetDuration("openai.reasoning.timeout")
 	appConfig.OpenAI.Reasoning.MaxTokens = viper.GetInt64("openai.reasoning.max_tokens")
 	appConfig.OpenAI.Completion.Model = viper.GetString("openai.completion.model")
 	appConfig.OpenAI.Completion.Timeout = viper.GetDuration("openai.completion.timeout")
 	appConfig.OpenAI.Completion.MaxTokens = viper.GetInt64("openai.completion.max_tokens")
+	if err := viper.UnmarshalKey("openai.models", &appConfig.OpenAI.Models); err != nil {
+		return err
+	}
+
+	appConfig.Anthropic.APIKey = viper.GetString("anthropic.api_key")
+	appConfig.Anthropic.BaseURL = viper.GetString("anthropic.base_url")
+	appConfig.Anthropic.Retry = getRetry("anthropic.retry")
+	appConfig.Anthropic.Reasoning.Model = viper.GetString("anthropic.reasoning.model")
+	appConfig.Anthropic.Reasoning.Timeout = viper.GetDuration("anthropic.reasoning.timeout")
+	appConfig.Anthropic.Reasoning.MaxTokens = viper.GetInt64("anthropic.reasoning.max_tokens")
+	appConfig.Anthropic.Completion.Model = viper.GetString("anthropic.completion.model")
+	appConfig.Anthropic.Completion.Timeout = viper.GetDuration("anthropic.completion.timeout")
+	appConfig.Anthropic.Completion.MaxTokens = viper.GetInt64("anthropic.completion.max_tokens")
 
 	appConfig.WebSearch.Tavily.Timeout = viper.GetDuration("websearch.tavily.timeout")
 	appConfig.WebSearch.Tavily.APIKey = viper.GetString("websearch.tavily.api_key")
@@ -104,8 +317,73 @@ func setValues() {
 	appConfig.WebSearch.Tavily.ExtractURL = viper.GetString("websearch.tavily.extract_url")
 	appConfig.WebSearch.Tavily.MaxResults = viper.GetInt("websearch.tavily.max_results")
 
+	appConfig.WebSearch.Provider = viper.GetString("websearch.provider")
+	appConfig.WebSearch.Retry = getRetry("websearch.retry")
+	appConfig.WebSearch.SearXNG.Timeout = viper.GetDuration("websearch.searxng.timeout")
+	appConfig.WebSearch.SearXNG.URL = viper.GetString("websearch.searxng.url")
+	appConfig.WebSearch.SearXNG.Categories = viper.GetString("websearch.searxng.categories")
+	appConfig.WebSearch.SearXNG.Language = viper.GetString("websearch.searxng.language")
+	appConfig.WebSearch.SearXNG.MaxResults = viper.GetInt("websearch.searxng.max_results")
+	appConfig.WebSearch.Brave.Timeout = viper.GetDuration("websearch.brave.timeout")
+	appConfig.WebSearch.Brave.APIKey = viper.GetString("websearch.brave.api_key")
+	appConfig.WebSearch.Brave.SearchURL = viper.GetString("websearch.brave.search_url")
+	appConfig.WebSearch.Brave.MaxResults = viper.GetInt("websearch.brave.max_results")
+	appConfig.WebSearch.Bing.Timeout = viper.GetDuration("websearch.bing.timeout")
+	appConfig.WebSearch.Bing.APIKey = viper.GetString("websearch.bing.api_key")
+	appConfig.WebSearch.Bing.SearchURL = viper.GetString("websearch.bing.search_url")
+	appConfig.WebSearch.Bing.Market = viper.GetString("websearch.bing.market")
+	appConfig.WebSearch.Bing.MaxResults = viper.GetInt("websearch.bing.max_results")
+	appConfig.WebSearch.Google.Timeout = viper.GetDuration("websearch.google.timeout")
+	appConfig.WebSearch.Google.APIKey = viper.GetString("websearch.google.api_key")
+	appConfig.WebSearch.Google.CX = viper.GetString("websearch.google.cx")
+	appConfig.WebSearch.Google.SearchURL = viper.GetString("websearch.google.search_url")
+	appConfig.WebSearch.Google.MaxResults = viper.GetInt("websearch.google.max_results")
+	appConfig.WebSearch.DuckDuckGo.Timeout = viper.GetDuration("websearch.duckduckgo.timeout")
+	appConfig.WebSearch.DuckDuckGo.SearchURL = viper.GetString("websearch.duckduckgo.search_url")
+	appConfig.WebSearch.DuckDuckGo.Region = viper.GetString("websearch.duckduckgo.region")
+	appConfig.WebSearch.DuckDuckGo.MaxResults = viper.GetInt("websearch.duckduckgo.max_results")
+	appConfig.WebSearch.Fusion.Providers = viper.GetStringSlice("websearch.fusion.providers")
+	appConfig.WebSearch.Fusion.MaxResults = viper.GetInt("websearch.fusion.max_results")
+
 	appConfig.WebReader.Timeout = viper.GetDuration("webreader.timeout")
 	appConfig.WebReader.MinContentLength = viper.GetInt("webreader.min_content_length")
+	appConfig.WebReader.Retry = getRetry("webreader.retry")
+
+	appConfig.Search.Concurrency = viper.GetInt("search.concurrency")
+	appConfig.Search.Deepening.MaxDepth = viper.GetInt("search.deepening.max_depth")
+	appConfig.Search.Deepening.MaxQueries = viper.GetInt("search.deepening.max_queries")
+
+	appConfig.Relevance.Enabled = viper.GetBool("relevance.enabled")
+	appConfig.Relevance.Provider = viper.GetString("relevance.provider")
+	appConfig.Relevance.Model = viper.GetString("relevance.model")
+	appConfig.Relevance.BaseURL = viper.GetString("relevance.base_url")
+	appConfig.Relevance.APIKey = viper.GetString("relevance.api_key")
+	appConfig.Relevance.Threshold = viper.GetFloat64("relevance.threshold")
+	appConfig.Relevance.TopK = viper.GetInt("relevance.top_k")
+	appConfig.Relevance.ChunkTokens = viper.GetInt64("relevance.chunk_tokens")
+
+	appConfig.Session.Dir = viper.GetString("session.dir")
+
+	appConfig.Server.Address = viper.GetString("server.address")
+	appConfig.Server.RequestTimeout = viper.GetDuration("server.request_timeout")
+	appConfig.Server.JobTimeout = viper.GetDuration("server.job_timeout")
+	appConfig.Server.MaxRequests = viper.GetInt("server.max_requests")
+	appConfig.Server.MaxReadURLs = viper.GetInt("server.max_read_urls")
+	appConfig.Server.MaxJobs = viper.GetInt("server.max_jobs")
+	appConfig.Server.MaxQueued = viper.GetInt("server.max_queued")
+	appConfig.Server.JobTTL = viper.GetDuration("server.job_ttl")
+
+	appConfig.MCP.MaxResearch = viper.GetInt("mcp.max_research")
+
+	appConfig.Budget.MaxTokens = viper.GetInt64("budget.max_tokens")
+	appConfig.Budget.MaxCost = viper.GetFloat64("budget.max_cost")
+	appConfig.Budget.MaxPages = viper.GetInt("budget.max_pages")
+	appConfig.Budget.MaxTime = viper.GetDuration("budget.max_time")
+	if err := viper.UnmarshalKey("budget.prices", &appConfig.Budget.Prices); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func Get() *Config {
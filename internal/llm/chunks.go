@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SplitMarkdown splits the markdown text into chunks of at most maxTokens estimated tokens.
+// The text is split along headings, sections larger than a chunk are split along
+// paragraphs, and paragraphs larger than a chunk are cut. Adjacent pieces are
+// packed into the same chunk while they fit.
+func SplitMarkdown(text string, maxTokens int64) []string {
+	if EstimateTokens(text) <= maxTokens {
+		return []string{text}
+	}
+
+	var pieces []string
+	for _, section := range splitSections(text) {
+		if EstimateTokens(section) <= maxTokens {
+			pieces = append(pieces, section)
+			continue
+		}
+		// keep the heading with the first paragraph of the section
+		paragraphs := strings.SplitAfter(section, "\n\n")
+		if len(paragraphs) > 1 && strings.HasPrefix(paragraphs[0], "#") {
+			paragraphs[1] = paragraphs[0] + paragraphs[1]
+			paragraphs = paragraphs[1:]
+		}
+		for _, paragraph := range paragraphs {
+			pieces = append(pieces, cutText(paragraph, maxTokens)...)
+		}
+	}
+
+	var chunks []string
+	var chunk strings.Builder
+	for _, piece := range pieces {
+		if chunk.Len() > 0 && EstimateTokens(chunk.String()+piece) > maxTokens {
+			chunks = append(chunks, chunk.String())
+			chunk.Reset()
+		}
+		chunk.WriteString(piece)
+	}
+	if strings.TrimSpace(chunk.String()) != "" {
+		chunks = append(chunks, chunk.String())
+	}
+
+	return chunks
+}
+
+// splitSections splits the markdown text before every heading line.
+func splitSections(text string) []string {
+	var sections []string
+	var section strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if strings.HasPrefix(line, "#") && section.Len() > 0 {
+			sections = append(sections, section.String())
+			section.Reset()
+		}
+		section.WriteString(line)
+	}
+	if section.Len() > 0 {
+		sections = append(sections, section.String())
+	}
+	return sections
+}
+
+// cutText cuts the text into pieces of at most maxTokens estimated tokens
+// keeping UTF-8 characters whole.
+func cutText(text string, maxTokens int64) []string {
+	size := int(maxTokens * charsPerToken)
+	if size < utf8.UTFMax {
+		size = utf8.UTFMax
+	}
+
+	var pieces []string
+	for len(text) > size {
+		cut := size
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		pieces = append(pieces, text[:cut])
+		text = text[cut:]
+	}
+	if text != "" {
+		pieces = append(pieces, text)
+	}
+	return pieces
+}
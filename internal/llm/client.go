@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Client implements LLM operations on top of a model provider.
+type Client struct {
+	completer         Completer     // Model provider
+	logger            *zap.Logger   // Logger
+	reasoningTimeout  time.Duration // Timeout for reasoning
+	completionTimeout time.Duration // Timeout for completion
+
+	analysisResultSchema     *Schema // Schema for analysis result
+	compilationResultSchema  *Schema // Schema for compilation result
+	verificationResultSchema *Schema // Schema for verification result
+	followUpResultSchema     *Schema // Schema for follow-up plan
+	gapResultSchema          *Schema // Schema for findings assessment
+}
+
+// NewClient creates a new LLM client with the model provider, logger and timeouts.
+// It returns a pointer to the client.
+func NewClient(
+	completer Completer,
+	logger *zap.Logger,
+	reasoningTimeout time.Duration,
+	completionTimeout time.Duration,
+) *Client {
+	return &Client{
+		completer:         completer,
+		logger:            logger,
+		reasoningTimeout:  reasoningTimeout,
+		completionTimeout: completionTimeout,
+		analysisResultSchema: &Schema{
+			Name:        "PageAnalysis",
+			Description: "Relevance and key points from the page",
+			Schema:      GenerateSchema[AnalysisResult](),
+		},
+		compilationResultSchema: &Schema{
+			Name:        "PageAnalysis",
+			Description: "Relevance and key points from the page",
+			Schema:      GenerateSchema[CompilationResult](),
+		},
+		verificationResultSchema: &Schema{
+			Name:        "ClaimVerification",
+			Description: "Verdict of the claim check against the sources",
+			Schema:      GenerateSchema[VerificationResult](),
+		},
+		followUpResultSchema: &Schema{
+			Name:        "FollowUpPlan",
+			Description: "Decision how to answer the follow-up question",
+			Schema:      GenerateSchema[FollowUpResult](),
+		},
+		gapResultSchema: &Schema{
+			Name:        "FindingsAssessment",
+			Description: "Assessment of the findings and refined search queries",
+			Schema:      GenerateSchema[GapResult](),
+		},
+	}
+}
@@ -0,0 +1,259 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dimdasci/seek/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPromptLimit = 16000 // Prompt tokens of the models with an unknown context window
+	minChunkTokens     = 1000  // Min tokens of page content analysed in a single call
+)
+
+// AnalyzePage analyzes if the page contains information relevant to the request.
+// Pages that do not fit the context window of the model are split into chunks
+// along markdown headings, every chunk is analysed separately, and the key points
+// of the relevant chunks are merged.
+// It returns relevance and the key points from the page.
+func (c *Client) AnalyzePage(
+	ctx context.Context,
+	page *models.Page,
+	request *string,
+	instructions *string,
+) (relevant bool, keyPoints string, err error) {
+	// the content fills the prompt left by the instructions,
+	// a quarter is kept as the margin of the rough token estimation
+	limit := c.completer.PromptLimit(TierCompletion)
+	if limit <= 0 {
+		limit = defaultPromptLimit
+	}
+	overhead := EstimateTokens(relevanceSystemPrompt + analysisPrompt(page, "", "", *request, *instructions))
+	chunks := SplitMarkdown(page.Content, max((limit-overhead)*3/4, minChunkTokens))
+	if len(chunks) == 1 {
+		return c.analyzeChunk(ctx, page, page.Content, "", *request, *instructions)
+	}
+
+	c.logger.Info("Page is split into chunks",
+		zap.String("url", page.URL),
+		zap.Int64("tokens", EstimateTokens(page.Content)),
+		zap.Int("chunks", len(chunks)))
+
+	var points []string
+	var failed int
+	for i, chunk := range chunks {
+		part := fmt.Sprintf("The content is the part %d of %d of the page.", i+1, len(chunks))
+		ok, chunkPoints, chunkErr := c.analyzeChunk(ctx, page, chunk, part, *request, *instructions)
+		if chunkErr != nil {
+			if ctx.Err() != nil {
+				return false, "", chunkErr
+			}
+			failed++
+			err = chunkErr
+			continue
+		}
+		if ok {
+			relevant = true
+			points = append(points, chunkPoints)
+		}
+	}
+	if failed == len(chunks) {
+		return false, "", err
+	}
+
+	return relevant, mergeKeyPoints(points), nil
+}
+
+// analyzeChunk analyzes if the content of the page is relevant to the request.
+// The part describes the position of the chunk in the page, empty for the whole page.
+// It returns relevance and the key points from the content.
+func (c *Client) analyzeChunk(
+	ctx context.Context,
+	page *models.Page,
+	content string,
+	part string,
+	request string,
+	instructions string,
+) (bool, string, error) {
+	prompt := analysisPrompt(page, content, part, request, instructions)
+
+	c.logger.Debug("Relevance", zap.String("user_prompt", prompt))
+
+	// add timeout to the context
+	ctx, cancel := context.WithTimeout(ctx, c.reasoningTimeout)
+	defer cancel()
+
+	chat, err := c.complete(ctx, &Request{
+		Name:        "analyze page",
+		Tier:        TierCompletion,
+		System:      relevanceSystemPrompt,
+		Prompt:      prompt,
+		Schema:      c.analysisResultSchema,
+		Temperature: Float(0.1),
+	})
+
+	if err != nil {
+		c.logger.Error("failed to analyze page",
+			zap.Error(err),
+			zap.String("url", page.URL),
+			zap.String("title", page.Title))
+		return false, "", err
+
+	}
+
+	// Log completion stats
+	c.logger.Info("Page Analysis",
+		zap.String("reason", chat.FinishReason),
+		zap.String("model", chat.Model),
+		zap.Int64("input tokens", chat.PromptTokens),
+		zap.Int64("completion tokens", chat.CompletionTokens),
+		zap.Int64("max tokens", chat.MaxTokens),
+	)
+
+	// create result from chat response
+	result := AnalysisResult{}
+	err = json.Unmarshal([]byte(chat.Content), &result)
+	if err != nil {
+		c.logger.Error("failed to unmarshal chat response",
+			zap.Error(err),
+			zap.String("completion", chat.Content))
+		return false, "", err
+	}
+
+	c.logger.Debug("Page Analysis Done",
+		zap.String("url", page.URL),
+		zap.String("title", page.Title),
+		zap.Bool("relevance", result.Relevance),
+		zap.String("answer", result.Answer))
+
+	return result.Relevance, result.Answer, nil
+}
+
+// analysisPrompt returns the user prompt of the page content analysis.
+func analysisPrompt(page *models.Page, content, part, request, instructions string) string {
+	// create a string with today's date
+	today := fmt.Sprintf("%d-%02d-%02d", time.Now().Year(), time.Now().Month(), time.Now().Day())
+	if part != "" {
+		part += "\n\n"
+	}
+	return fmt.Sprintf("%v\n\n"+
+		"Today is %v.\n\n"+
+		"%v"+
+		"<source_id>%v<source_id>"+
+		"<title>%v<title>"+
+		"<url>%v<url>"+
+		"<content>%v<content>"+
+		"<information_request>%v<information_request>"+
+		"<compilation_instruction>%v<compilation_instruction>",
+		relevanceUserPrompt,
+		today,
+		part,
+		page.ID,
+		page.Title,
+		page.URL,
+		content,
+		request,
+		instructions)
+}
+
+// mergeKeyPoints merges the key points of the page chunks into a single text
+// under the page title of the first chunk.
+func mergeKeyPoints(points []string) string {
+	for i := 1; i < len(points); i++ {
+		if strings.HasPrefix(points[i], "# ") {
+			_, rest, _ := strings.Cut(points[i], "\n")
+			points[i] = rest
+		}
+	}
+	return strings.Join(points, "\n\n")
+}
+
+// fitPrompt returns the leading chunk of the text that fits the prompt of the tier model
+// left by the overhead, the rest of the text is dropped.
+func (c *Client) fitPrompt(tier Tier, text string, overhead string) string {
+	// a quarter is kept as the margin of the rough token estimation
+	limit := c.completer.PromptLimit(tier)
+	if limit <= 0 {
+		limit = defaultPromptLimit
+	}
+	chunks := SplitMarkdown(text, max((limit-EstimateTokens(overhead))*3/4, minChunkTokens))
+	if len(chunks) > 1 {
+		c.logger.Warn("Text is cut to fit the prompt",
+			zap.Int64("tokens", EstimateTokens(text)),
+			zap.Int64("kept", EstimateTokens(chunks[0])))
+	}
+	return chunks[0]
+}
+
+// compilationUserPrompt returns the user prompt of the findings compilation.
+func compilationUserPrompt(results, topic, policy string) string {
+	return fmt.Sprintf("%v\n\n"+
+		"<information_topic>%v<information_topic>"+
+		"<search_results>%v<search_results>"+
+		"<compilation_policy>%v<compilation_policy>",
+		compilationPrompt,
+		topic,
+		results,
+		policy)
+}
+
+// CompileFindings compiles the search results on the topic following the policy.
+// Results that do not fit the prompt are cut, the leading ones are kept.
+// It returns a string with the compiled findings and an error if any.
+func (c *Client) CompileFindings(ctx context.Context, results string, topic string, policy string) (string, error) {
+	c.logger.Info("Compiling findings", zap.String("topic", topic))
+
+	results = c.fitPrompt(TierCompletion, results, relevanceSystemPrompt+compilationUserPrompt("", topic, policy))
+	prompt := compilationUserPrompt(results, topic, policy)
+
+	c.logger.Debug("Compilation", zap.String("prompt", prompt))
+
+	// add timeout to the context
+	ctx, cancel := context.WithTimeout(ctx, c.completionTimeout)
+	defer cancel()
+
+	chat, err := c.complete(ctx, &Request{
+		Name:        "compile findings",
+		Tier:        TierCompletion,
+		System:      relevanceSystemPrompt,
+		Prompt:      prompt,
+		Schema:      c.compilationResultSchema,
+		Temperature: Float(0.1),
+	})
+
+	if err != nil {
+		c.logger.Error("failed to compile findings",
+			zap.Error(err),
+			zap.String("topic", topic))
+		return "", fmt.Errorf("failed to compile findings: %w", err)
+	}
+
+	// Log completion stats
+	c.logger.Info("Compilation",
+		zap.String("reason", chat.FinishReason),
+		zap.String("model", chat.Model),
+		zap.Int64("input tokens", chat.PromptTokens),
+		zap.Int64("completion tokens", chat.CompletionTokens),
+		zap.Int64("max tokens", chat.MaxTokens))
+
+	// create result from chat response
+	result := CompilationResult{}
+	err = json.Unmarshal([]byte(chat.Content), &result)
+	if err != nil {
+		c.logger.Error("failed to unmarshal chat response",
+			zap.Error(err),
+			zap.String("completion", chat.Content))
+		return "", fmt.Errorf("failed to parse compiled findings: %w", err)
+	}
+
+	c.logger.Debug("Compilation Done",
+		zap.String("topic", topic),
+		zap.String("compilation", result.Compilation))
+
+	return result.Compilation, nil
+}
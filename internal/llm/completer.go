@@ -0,0 +1,77 @@
+package llm
+
+import "context"
+
+// Tier selects the model used for a completion request.
+type Tier string
+
+const (
+	TierReasoning  Tier = "reasoning"  // Model for planning and reasoning
+	TierCompletion Tier = "completion" // Model for analysis and compilation
+)
+
+const (
+	FinishReasonLength        = "length"         // Completion is cut by the token limit
+	FinishReasonContentFilter = "content_filter" // Completion is blocked by the content filter
+)
+
+// Schema describes a structured output requested from the model.
+type Schema struct {
+	Name        string      // Name of the schema
+	Description string      // Description of the expected output
+	Schema      interface{} // JSON schema of the output
+}
+
+// Request represents a single chat completion request.
+type Request struct {
+	Name        string   // Name of the call in logs and usage reports
+	Tier        Tier     // Model tier to use
+	System      string   // System prompt, empty if not needed
+	Prompt      string   // User prompt
+	Schema      *Schema  // Structured output schema, nil for free text
+	JSON        bool     // Free text output is parsed as JSON
+	Temperature *float64 // Sampling temperature, nil for the model default
+	MaxTokens   int64    // Completion token limit, 0 for the tier limit
+	Partial     string   // Truncated output the model continues, empty for a new completion
+
+	// Stream receives the free text output as it is generated,
+	// nil waits for the whole completion.
+	Stream func(text string)
+}
+
+// structured reports whether the output of the request is parsed as JSON
+// and cannot be continued when truncated.
+func (r *Request) structured() bool {
+	return r.Schema != nil || r.JSON
+}
+
+// Response represents a chat completion response.
+type Response struct {
+	Content          string // Text of the completion
+	Refusal          string // Refusal message of the model, empty if it answered
+	FinishReason     string // Reason the model stopped generating
+	Model            string // Model that served the request
+	PromptTokens     int64  // Number of input tokens
+	CompletionTokens int64  // Number of generated tokens
+	MaxTokens        int64  // Token limit of the completion
+}
+
+// Embeddings represents the embedding vectors of texts in the texts order.
+type Embeddings struct {
+	Vectors [][]float64
+	Model   string // Model that embedded the texts, empty for local embeddings
+	Tokens  int64  // Number of input tokens
+}
+
+// Completer sends chat completion requests to a model provider.
+type Completer interface {
+	Complete(ctx context.Context, req *Request) (*Response, error)
+
+	// PromptLimit returns the max prompt tokens of the tier model, 0 if unknown.
+	PromptLimit(tier Tier) int64
+}
+
+// Float returns a pointer to the float value.
+func Float(v float64) *float64 {
+	return &v
+}
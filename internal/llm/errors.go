@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrRefused is returned when the model refuses the request.
+	ErrRefused = errors.New("model refused the request")
+	// ErrTruncated is returned when the completion is cut by the token limit.
+	ErrTruncated = errors.New("model output is truncated")
+)
+
+// Handling of the completions cut by the token limit
+const (
+	maxContinuations = 3 // Max continuations of a truncated free text completion
+	maxEscalations   = 2 // Max retries of a truncated structured completion
+	escalationFactor = 2 // Growth of the token limit on every structured retry
+)
+
+// complete sends the request to the model provider and checks the completion.
+// A truncated free text completion is continued, and a truncated structured
+// completion is retried with a larger token limit, within the limits above.
+// It returns ErrRefused if the model refused to answer, and ErrTruncated
+// with the response if the completion is still cut by the token limit.
+func (c *Client) complete(ctx context.Context, req *Request) (*Response, error) {
+	chat, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; chat.FinishReason == FinishReasonLength; attempt++ {
+		if req.structured() {
+			if attempt > maxEscalations {
+				break
+			}
+			retry := *req
+			retry.MaxTokens = chat.MaxTokens * escalationFactor
+
+			c.logger.Warn("Completion is truncated, retrying with a larger token limit",
+				zap.String("call", req.Name),
+				zap.Int64("max tokens", chat.MaxTokens),
+				zap.Int64("retry max tokens", retry.MaxTokens))
+
+			next, err := c.send(ctx, &retry)
+			if err != nil {
+				return nil, err
+			}
+			// the provider caps the limit by the model, a larger one is not available
+			grown := next.MaxTokens > chat.MaxTokens
+			chat = next
+			if !grown {
+				break
+			}
+			continue
+		}
+
+		if attempt > maxContinuations {
+			break
+		}
+		next := *req
+		next.Partial = chat.Content
+
+		c.logger.Warn("Completion is truncated, continuing the output",
+			zap.String("call", req.Name),
+			zap.Int("continuation", attempt),
+			zap.Int64("completion tokens", chat.CompletionTokens))
+
+		part, err := c.send(ctx, &next)
+		if err != nil {
+			return nil, err
+		}
+		chat = &Response{
+			Content:          chat.Content + part.Content,
+			FinishReason:     part.FinishReason,
+			Model:            part.Model,
+			PromptTokens:     chat.PromptTokens + part.PromptTokens,
+			CompletionTokens: chat.CompletionTokens + part.CompletionTokens,
+			MaxTokens:        part.MaxTokens,
+		}
+	}
+
+	if chat.FinishReason == FinishReasonLength {
+		c.logger.Error("Completion is truncated",
+			zap.String("call", req.Name),
+			zap.Int64("completion tokens", chat.CompletionTokens),
+			zap.Int64("max tokens", chat.MaxTokens))
+		return chat, fmt.Errorf("%w: %s, %d tokens", ErrTruncated, req.Name, chat.CompletionTokens)
+	}
+	return chat, nil
+}
+
+// send sends a single request to the model provider.
+// It returns ErrRefused if the model refused to answer.
+func (c *Client) send(ctx context.Context, req *Request) (*Response, error) {
+	chat, err := c.completer.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case chat.Refusal != "":
+		return nil, fmt.Errorf("%w: %s", ErrRefused, chat.Refusal)
+	case chat.FinishReason == FinishReasonContentFilter:
+		return nil, fmt.Errorf("%w: content filter", ErrRefused)
+	}
+	return chat, nil
+}
@@ -0,0 +1,122 @@
+// Package fake provides a deterministic LLM implementation for tests and offline runs.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dimdasci/seek/internal/models"
+)
+
+// Client is a deterministic LLM that never calls a model.
+// It plans a simple search for every query and compiles the inputs verbatim.
+type Client struct{}
+
+// NewClient creates a new fake LLM client.
+func NewClient() *Client {
+	return &Client{}
+}
+
+// PlanSearch returns a simple search plan with the query as the search query.
+func (c *Client) PlanSearch(ctx context.Context, query string) (*models.Plan, error) {
+	return &models.Plan{
+		Approved:          true,
+		Reason:            "fake plan",
+		SearchQuery:       query,
+		SearchComplexity:  "simple",
+		CompilationPolicy: "List the sources found for the request.",
+	}, nil
+}
+
+// RevisePlan returns a copy of the plan with the feedback as the reason.
+func (c *Client) RevisePlan(ctx context.Context, query string, plan *models.Plan, feedback string) (*models.Plan, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("previous plan is nil")
+	}
+	revised := *plan
+	revised.Reason = feedback
+	return &revised, nil
+}
+
+// PlanFollowUp treats the follow-up as covered if the findings mention it,
+// otherwise it searches for the follow-up question.
+func (c *Client) PlanFollowUp(ctx context.Context, query string, findings string, followUp string) (*models.FollowUpPlan, error) {
+	if strings.Contains(strings.ToLower(findings), strings.ToLower(followUp)) {
+		return &models.FollowUpPlan{
+			Covered:           true,
+			Reason:            "fake follow-up covered",
+			CompilationPolicy: "List the sources found for the request.",
+		}, nil
+	}
+	return &models.FollowUpPlan{
+		Reason:            "fake follow-up search",
+		SearchQueries:     []string{followUp},
+		CompilationPolicy: "List the sources found for the request.",
+	}, nil
+}
+
+// AnalyzePage marks every page as relevant and lists it citing its source ID.
+func (c *Client) AnalyzePage(
+	ctx context.Context,
+	page *models.Page,
+	request *string,
+	instructions *string,
+) (bool, string, error) {
+	return true, fmt.Sprintf("- %s [%s]", page.Title, page.ID), nil
+}
+
+// CompileFindings returns the results under the topic header.
+func (c *Client) CompileFindings(ctx context.Context, results string, topic string, policy string) (string, error) {
+	return fmt.Sprintf("## %s\n\n%s", topic, strings.TrimSpace(results)), nil
+}
+
+// AssessFindings treats any non-empty findings as answering the request,
+// and refines empty findings with the request as the query.
+func (c *Client) AssessFindings(
+	ctx context.Context,
+	request string,
+	policy string,
+	findings string,
+	queries []string,
+) (*models.Gap, error) {
+	if strings.TrimSpace(findings) != "" {
+		return &models.Gap{Answered: true}, nil
+	}
+	return &models.Gap{
+		Missing: "no relevant pages",
+		Queries: []string{fmt.Sprintf("%s overview %d", request, len(queries))},
+	}, nil
+}
+
+// WriteReport returns the findings under the request title.
+// The report is streamed line by line.
+func (c *Client) WriteReport(
+	ctx context.Context,
+	findings *string,
+	request *string,
+	plan *string,
+	instructions *string,
+	stream func(text string),
+) (string, error) {
+	if findings == nil || request == nil {
+		return "", fmt.Errorf("findings and request are required")
+	}
+	report := fmt.Sprintf("# %s\n\n%s\n", *request, strings.TrimSpace(*findings))
+	if stream != nil {
+		for _, line := range strings.SplitAfter(report, "\n") {
+			stream(line)
+		}
+	}
+	return report, nil
+}
+
+// VerifyClaim supports the claim if any page contains its text.
+func (c *Client) VerifyClaim(ctx context.Context, claim string, pages []models.Page) (models.Verdict, string, error) {
+	for _, p := range pages {
+		if strings.Contains(strings.ToLower(p.Content), strings.ToLower(claim)) {
+			return models.VerdictSupported, fmt.Sprintf("Found in %s", p.URL), nil
+		}
+	}
+	return models.VerdictUnsupported, "Not found in the sources", nil
+}
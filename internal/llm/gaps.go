@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dimdasci/seek/internal/models"
+	"go.uber.org/zap"
+)
+
+// AssessFindings checks if the findings answer the request following the policy.
+// It returns the missing information and refined search queries if they do not.
+func (c *Client) AssessFindings(
+	ctx context.Context,
+	request string,
+	policy string,
+	findings string,
+	queries []string,
+) (*models.Gap, error) {
+	prompt := fmt.Sprintf("%v\n\n"+
+		"<information_request>%v<information_request>"+
+		"<compilation_policy>%v<compilation_policy>"+
+		"<findings>%v<findings>"+
+		"<used_queries>%v<used_queries>",
+		gapPrompt,
+		request,
+		policy,
+		findings,
+		strings.Join(queries, "\n"))
+
+	c.logger.Debug("Findings assessment", zap.String("prompt", prompt))
+
+	// add timeout to the context
+	ctx, cancel := context.WithTimeout(ctx, c.completionTimeout)
+	defer cancel()
+
+	chat, err := c.complete(ctx, &Request{
+		Name:        "assess findings",
+		Tier:        TierCompletion,
+		System:      relevanceSystemPrompt,
+		Prompt:      prompt,
+		Schema:      c.gapResultSchema,
+		Temperature: Float(0.1),
+	})
+	if err != nil {
+		c.logger.Error("failed to assess findings",
+			zap.Error(err),
+			zap.String("request", request))
+		return nil, err
+	}
+
+	// Log completion stats
+	c.logger.Info("Findings assessment",
+		zap.String("reason", chat.FinishReason),
+		zap.String("model", chat.Model),
+		zap.Int64("input tokens", chat.PromptTokens),
+		zap.Int64("completion tokens", chat.CompletionTokens),
+		zap.Int64("max tokens", chat.MaxTokens))
+
+	result := GapResult{}
+	if err := json.Unmarshal([]byte(chat.Content), &result); err != nil {
+		c.logger.Error("failed to unmarshal chat response",
+			zap.Error(err),
+			zap.String("completion", chat.Content))
+		return nil, err
+	}
+
+	c.logger.Debug("Findings assessment done",
+		zap.String("request", request),
+		zap.Bool("answered", result.Answered),
+		zap.String("missing", result.Missing),
+		zap.Strings("queries", result.Queries))
+
+	return &models.Gap{
+		Answered: result.Answered,
+		Missing:  result.Missing,
+		Queries:  result.Queries,
+	}, nil
+}
@@ -0,0 +1,28 @@
+// Package llm provides the language model operations of the search pipeline.
+package llm
+
+import (
+	"context"
+
+	"github.com/dimdasci/seek/internal/models"
+)
+
+// LLM defines the language model operations used by the search service.
+type LLM interface {
+	// PlanSearch builds a search plan for the query.
+	PlanSearch(ctx context.Context, query string) (*models.Plan, error)
+	// RevisePlan builds a new search plan from the previous plan and the reviewer feedback.
+	RevisePlan(ctx context.Context, query string, plan *models.Plan, feedback string) (*models.Plan, error)
+	// PlanFollowUp decides if the research findings cover the follow-up question.
+	PlanFollowUp(ctx context.Context, query string, findings string, followUp string) (*models.FollowUpPlan, error)
+	// AnalyzePage checks the page relevance to the request and extracts its key points.
+	AnalyzePage(ctx context.Context, page *models.Page, request *string, instructions *string) (bool, string, error)
+	// CompileFindings compiles search results on the topic following the policy.
+	CompileFindings(ctx context.Context, results string, topic string, policy string) (string, error)
+	// AssessFindings checks if the findings answer the request and refines the search queries.
+	AssessFindings(ctx context.Context, request string, policy string, findings string, queries []string) (*models.Gap, error)
+	// WriteReport writes the final report from the findings, streaming its text unless the stream is nil.
+	WriteReport(ctx context.Context, findings *string, request *string, plan *string, instructions *string, stream func(text string)) (string, error)
+	// VerifyClaim checks the claim against the source pages.
+	VerifyClaim(ctx context.Context, claim string, pages []models.Page) (models.Verdict, string, error)
+}
@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dimdasci/seek/internal/models"
+	"go.uber.org/zap"
+)
+
+// PlanSearch builds a search plan for given query, returns it and an error if any.
+func (c *Client) PlanSearch(ctx context.Context, query string) (*models.Plan, error) {
+
+	// create a string with today's date
+	today := fmt.Sprintf("%d-%02d-%02d", time.Now().Year(), time.Now().Month(), time.Now().Day())
+	prompt := fmt.Sprintf("%v\n\nToday is %v.\n\n<information_request>%v<information_request>", planningPrompt, today, query)
+
+	return c.plan(ctx, query, prompt)
+}
+
+// RevisePlan builds a new search plan for the query from the previous plan
+// and the reviewer feedback, returns it and an error if any.
+func (c *Client) RevisePlan(ctx context.Context, query string, plan *models.Plan, feedback string) (*models.Plan, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("previous plan is nil")
+	}
+
+	// create a string with today's date
+	today := fmt.Sprintf("%d-%02d-%02d", time.Now().Year(), time.Now().Month(), time.Now().Day())
+	prompt := fmt.Sprintf("%v\n\n%v\n\nToday is %v.\n\n"+
+		"<information_request>%v<information_request>\n\n"+
+		"<previous_plan>%v<previous_plan>\n\n"+
+		"<feedback>%v<feedback>",
+		planningPrompt,
+		revisionPrompt,
+		today,
+		query,
+		plan.String(),
+		feedback)
+
+	c.logger.Info("Revising search plan", zap.String("feedback", feedback))
+
+	return c.plan(ctx, query, prompt)
+}
+
+// PlanFollowUp decides if the research findings cover the follow-up question
+// or new web searches are required, returns the decision and an error if any.
+// Findings that do not fit the prompt are cut, the leading ones are kept.
+func (c *Client) PlanFollowUp(ctx context.Context, query string, findings string, followUp string) (*models.FollowUpPlan, error) {
+	findings = c.fitPrompt(TierReasoning, findings, followUpUserPrompt(query, "", followUp))
+	prompt := followUpUserPrompt(query, findings, followUp)
+
+	c.logger.Debug("Follow-up plan", zap.String("prompt", prompt))
+
+	// add timeout to the context
+	ctx, cancel := context.WithTimeout(ctx, c.reasoningTimeout)
+	defer cancel()
+
+	chat, err := c.complete(ctx, &Request{
+		Name:   "plan follow-up",
+		Tier:   TierReasoning,
+		Prompt: prompt,
+		Schema: c.followUpResultSchema,
+	})
+	if err != nil {
+		c.logger.Error("failed to plan follow-up",
+			zap.Error(err),
+			zap.String("follow_up", followUp))
+		return nil, err
+	}
+
+	// Log completion stats
+	c.logger.Info("Follow-up plan",
+		zap.String("reason", chat.FinishReason),
+		zap.String("model", chat.Model),
+		zap.Int64("input tokens", chat.PromptTokens),
+		zap.Int64("completion tokens", chat.CompletionTokens),
+		zap.Int64("max tokens", chat.MaxTokens))
+
+	result := FollowUpResult{}
+	if err := json.Unmarshal([]byte(chat.Content), &result); err != nil {
+		c.logger.Error("failed to unmarshal chat response",
+			zap.Error(err),
+			zap.String("completion", chat.Content))
+		return nil, err
+	}
+
+	if !result.Covered && len(result.SearchQueries) == 0 {
+		return nil, fmt.Errorf("follow-up is not covered and has no search queries")
+	}
+
+	return &models.FollowUpPlan{
+		Covered:           result.Covered,
+		Reason:            result.Reason,
+		SearchQueries:     result.SearchQueries,
+		CompilationPolicy: result.CompilationPolicy,
+	}, nil
+}
+
+// followUpUserPrompt returns the user prompt of the follow-up planning.
+func followUpUserPrompt(query, findings, followUp string) string {
+	return fmt.Sprintf("%v\n\n"+
+		"<information_request>%v<information_request>\n\n"+
+		"<findings>%v<findings>\n\n"+
+		"<follow_up_question>%v<follow_up_question>",
+		followUpPrompt,
+		query,
+		findings,
+		followUp)
+}
+
+// plan requests the search plan with the prompt and parses the response.
+func (c *Client) plan(ctx context.Context, query string, prompt string) (*models.Plan, error) {
+	// add timeout to the context
+	ctx, cancel := context.WithTimeout(ctx, c.reasoningTimeout)
+	defer cancel()
+
+	chat, err := c.complete(ctx, &Request{
+		Name:   "plan search",
+		Tier:   TierReasoning,
+		Prompt: prompt,
+		JSON:   true,
+	})
+
+	if err != nil {
+		c.logger.Error("failed to get completion",
+			zap.Error(err),
+			zap.String("query", query))
+		return nil, err
+	}
+
+	// Log completion reason
+	c.logger.Info("Search plan completion reason",
+		zap.String("reason", chat.FinishReason),
+		zap.String("model", chat.Model),
+		zap.Int64("completion tokens", chat.CompletionTokens),
+		zap.Int64("max tokens", chat.MaxTokens),
+	)
+
+	// create plan from chat response
+	searchPlan, err := models.NewPlan(chat.Content)
+	if err != nil {
+		c.logger.Error("failed to create plan from chat response",
+			zap.Error(err),
+			zap.String("query", query))
+		return nil, err
+	}
+
+	return searchPlan, nil
+}
@@ -1,6 +1,4 @@
-// Package openai provides the OpenAI client implementation.
-// It allows to interact with the OpenAI API to generate text based on the provided prompt.
-package openai
+package llm
 
 // planningPrompt is the prompt to generate a search plan for a given request.
 const planningPrompt string = `<instructions>
@@ -11,13 +9,16 @@ For simple requests, the plan should state that the search is simple and include
 For complex requests, the plan should:
 1. Clearly state that the request is complex.
 2. Include a list of tuples with the following details:
+  - ID: A short unique identifier of the step.
   - Topic: The main subject of the request.
   - Search Query: The most relevant web search term for gathering information.
   - Sub-request: Instructions for conducting the search and collecting information.
   - Answer Outline: A structure for the final answer, based on the information collected.
+  - Depends On: A list of IDs of the steps whose findings are required by the step, empty for independent steps.
 
 If analyzing results from previous steps is required:
-- Use the original information request as the search query.
+- Leave the search query empty.
+- List the IDs of the steps to analyze in depends on.
 - Provide specific instructions in the sub-request and outline for handling the analysis.
 
 
@@ -40,22 +41,36 @@ Here's an example of the expected JSON structure for a complex request:
   "search_query": "Describe banking system of Germany",
   "search_plan": [
     {
+      "id": "1",
       "topic": "Overview of the German Banking System",
       "search_query": "Overview of the banking system in Germany",
       "sub_request": "Gather general information about the structure, key institutions, and regulatory framework of the German banking system.",
-      "final_answer_outline": "Provide a comprehensive overview of the German banking system, including its historical development, main components, and the role of regulatory bodies."
+      "final_answer_outline": "Provide a comprehensive overview of the German banking system, including its historical development, main components, and the role of regulatory bodies.",
+      "depends_on": []
     },
     {
+      "id": "2",
       "topic": "Types of Banks in Germany",
       "search_query": "Types of banks in Germany",
       "sub_request": "Identify and describe the different types of banks operating in Germany, such as commercial banks, savings banks, cooperative banks, and specialized financial institutions.",
-      "final_answer_outline": "Detail the various categories of banks in Germany, their unique characteristics, functions, and examples of each type."
+      "final_answer_outline": "Detail the various categories of banks in Germany, their unique characteristics, functions, and examples of each type.",
+      "depends_on": []
     },
     {
+      "id": "3",
       "topic": "Grouping and Classification of German Banks",
       "search_query": "Classification of German banks by grouping",
       "sub_request": "Explore how German banks are grouped and classified based on factors like ownership, size, and services offered.",
-      "final_answer_outline": "Explain the grouping and classification criteria for German banks, including distinctions between public, private, and cooperative banks, as well as their market segments and roles within the financial system."
+      "final_answer_outline": "Explain the grouping and classification criteria for German banks, including distinctions between public, private, and cooperative banks, as well as their market segments and roles within the financial system.",
+      "depends_on": []
+    },
+    {
+      "id": "4",
+      "topic": "Role of Savings and Cooperative Banks",
+      "search_query": "",
+      "sub_request": "Analyze the findings on bank types and their classification to explain the role of savings and cooperative banks in the German banking system.",
+      "final_answer_outline": "Summarize how savings and cooperative banks differ from private commercial banks and why they matter for the German economy.",
+      "depends_on": ["2", "3"]
     }
   ],
   "compilation_policy": "Compile the gathered information into a structured and comprehensive report. Begin with an introduction that outlines the purpose and scope of the report. Follow with sections corresponding to each topic in the search plan, ensuring that each section addresses the sub-requests and incorporates the final answer outlines. Use reliable sources to verify information and cite them appropriately. Synthesize the data to provide clear and concise explanations, avoiding redundancy. Conclude the report with a summary that encapsulates the key findings and offers insights into the overall state and future of Germany's banking system. Ensure the final document is well-organized, logically flowing, and formatted professionally to meet the information request effectively."
@@ -80,6 +95,45 @@ For a simple request, the JSON structure would be:
 <example>
 `
 
+// revisionPrompt is the prompt to revise a search plan following the reviewer feedback.
+const revisionPrompt string = `<revision>
+The previous plan for the request was reviewed by the user. Revise the previous plan following the feedback.
+Keep the steps the feedback does not mention unchanged. Respond with the complete revised plan in the same JSON structure.
+<revision>
+`
+
+// followUpPrompt is the prompt to decide how to answer a follow-up question of a research.
+const followUpPrompt string = `<instructions>You are provided with:
+- information request of a completed web research,
+- findings of the research citing source IDs,
+- and a follow-up question.
+
+Decide if the findings contain enough information to answer the follow-up question. 
+If they do, set covered to true and leave the search queries empty. 
+If they do not, set covered to false and write up to 3 web search queries to find the missing information only.
+
+Write a policy to compile the answer to the follow-up question from the findings and the new search results.
+
+Before responding, describe the decision step by step according to the chain of reasoning.<instructions>
+`
+
+// gapPrompt is the prompt to assess the findings on a topic and refine the search.
+const gapPrompt string = `<instructions>You are provided with:
+- information request of a web search on a topic,
+- compilation policy,
+- findings compiled from the web pages found,
+- and the web search queries already used.
+
+Decide if the findings answer the information request following the compilation policy. 
+Empty findings mean that no relevant pages were found.
+
+If the findings answer the request, set answered to true and leave the queries empty. 
+If they do not, describe the missing information and write up to 3 refined web search queries to find it. 
+Do not repeat the queries already used, change the wording, the scope or the sources instead.
+
+Before responding, describe the assessment step by step according to the chain of reasoning.<instructions>
+`
+
 const relevanceSystemPrompt string = `You are an Information Discovery Expert specialized in finding accurate information from web sources.
                     
 Your task is to:
@@ -88,8 +142,9 @@ Your task is to:
 3. Compile a comprehensive answer with proper source attribution
 
 Focus on finding factual, verifiable information. Maintain a critical 
-perspective and evaluate the credibility of sources. Always include references
-to support your findings as inline quote or quotes of the given source.
+perspective and evaluate the credibility of sources. Always support your findings
+with the source ID in square brackets, for example [S3], right after the statement.
+Cite only source IDs given to you and never write URLs or lists of references.
 
 Use simple language. Avoid judgments, comparisons, and epithets. 
 `
@@ -109,8 +164,9 @@ Before responding, describe the analysis step by step according to the chain of
 If the page content is relevant to the information request, follow compilation instructions to compile the answer. 
 Otherwise provide the answer "Not relevant".
 
-Do not write any introduction or conclusion. Format the answer as a markdown text starting with the title of the page as h1 and the URL in the next line. 
+Do not write any introduction or conclusion. Format the answer as a markdown text starting with the title of the page as h1. 
 Then provide the extracted information using subheaders starting from level 2 if needed. Use bullet points carefully, only when the list is necessary.
+Put the source ID of the page in square brackets, for example [S3], after every statement taken from the page. Do not write the URL.
 <instructions>
 `
 
@@ -128,7 +184,8 @@ Do not write any introduction or conclusion regarding the quality of your work o
 Conclusions should only relate to the information request itself.
 
 Format your response as a section of the further report starting with topic as level 2 header. 
-Put up to 5 links to the most relevant sources in the end of the section as a list.
+Keep the source IDs in square brackets, for example [S3], after the statements they support. 
+Do not write links or a list of sources, they are added to the report automatically.
 
 Use markdown syntax to structure the report and provide the information in a clear and organized manner. 
 Use bullet points carefully, only when the list is necessary.<instructions>
@@ -149,4 +206,19 @@ Write a very short summary after the title to introduce the report.
 Then, provide the information in sections according to the search plan. 
 Use bullet points sparingly, only when a list is necessary.
 
-Include a list of references in each section to support the information provided.<instructions>`
+Keep the source IDs in square brackets, for example [S3], after the statements they support. 
+Do not write links or a list of references, the bibliography is added to the report automatically.<instructions>`
+
+const verificationPrompt string = `<instructions>You are provided with a claim from a research report and the sources the claim cites.
+
+Check the claim against the content of the sources only, do not use your own knowledge.
+
+Give one of the verdicts:
+- supported: the sources state the claim or directly imply it,
+- unsupported: the sources do not mention the claim or only partially support it,
+- contradicted: the sources state the opposite of the claim.
+
+Before responding, describe the check step by step according to the chain of reasoning.
+
+Explain the verdict in one or two sentences quoting the source if possible.<instructions>
+`
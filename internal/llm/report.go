@@ -1,25 +1,28 @@
-package openai
+package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
-	"github.com/openai/openai-go"
 	"go.uber.org/zap"
 )
 
 // WriteReport writes the final report for the request.
-// It returns a string with the final report.
+// The report text is passed to the stream as it is generated, unless the stream is nil.
+// It returns a string with the final report. A report cut by the token limit
+// is returned with an error wrapping ErrTruncated.
 func (c *Client) WriteReport(
 	ctx context.Context,
 	findings *string,
 	request *string,
 	plan *string,
 	instructions *string,
-) string {
+	stream func(text string),
+) (string, error) {
 	if findings == nil || request == nil || plan == nil || instructions == nil {
 		c.logger.Error("One or more input parameters are nil")
-		return "Failed to write report: one or more input parameters are nil"
+		return "", fmt.Errorf("failed to write report: one or more input parameters are nil")
 	}
 
 	c.logger.Info("Writing final report", zap.String("request", *request))
@@ -41,21 +44,24 @@ func (c *Client) WriteReport(
 	ctx, cancel := context.WithTimeout(ctx, c.reasoningTimeout)
 	defer cancel()
 
-	chat, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage(relevanceSystemPrompt),
-			openai.UserMessage(prompt),
-		}),
-		Model:               openai.F(c.completionModel),
-		MaxCompletionTokens: openai.Int(c.completionMaxTokens),
-		Temperature:         openai.Float(0.1),
+	chat, err := c.complete(ctx, &Request{
+		Name:        "write report",
+		Tier:        TierCompletion,
+		System:      relevanceSystemPrompt,
+		Prompt:      prompt,
+		Temperature: Float(0.1),
+		Stream:      stream,
 	})
 
+	if errors.Is(err, ErrTruncated) {
+		c.logger.Warn("Report is truncated", zap.Error(err))
+		return chat.Content, fmt.Errorf("failed to write report: %w", err)
+	}
 	if err != nil {
 		c.logger.Error("Failed to write report", zap.Error(err))
-		return fmt.Sprintf("Failed to write report: %v", err)
+		return "", fmt.Errorf("failed to write report: %w", err)
 	}
 
 	// compile findings
-	return chat.Choices[0].Message.Content
+	return chat.Content, nil
 }
@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"github.com/invopop/jsonschema"
+)
+
+// Structured output schemas
+type AnalysisResult struct {
+	ReasoningSteps []Step `json:"reasoning_steps" jsonschema_description:"The chain of reasoning"`
+	Relevance      bool   `json:"relevance" jsonschema_description:"The relevance of the page to the request"`
+	Answer         string `json:"answer" jsonschema_description:"The key points from the page"`
+}
+
+type CompilationResult struct {
+	ReasoningSteps []Step `json:"reasoning_steps" jsonschema_description:"The chain of reasoning"`
+	Compilation    string `json:"compilation" jsonschema_description:"The compiled result"`
+}
+
+type VerificationResult struct {
+	ReasoningSteps []Step `json:"reasoning_steps" jsonschema_description:"The chain of reasoning"`
+	Verdict        string `json:"verdict" jsonschema:"enum=supported,enum=unsupported,enum=contradicted" jsonschema_description:"The verdict of the claim check"`
+	Explanation    string `json:"explanation" jsonschema_description:"Short explanation of the verdict with a quote of the source"`
+}
+
+type FollowUpResult struct {
+	ReasoningSteps    []Step   `json:"reasoning_steps" jsonschema_description:"The chain of reasoning"`
+	Covered           bool     `json:"covered" jsonschema_description:"True if the findings answer the follow-up question"`
+	Reason            string   `json:"reason" jsonschema_description:"Short reason of the decision"`
+	SearchQueries     []string `json:"search_queries" jsonschema_description:"Web search queries for the missing information, empty if covered"`
+	CompilationPolicy string   `json:"compilation_policy" jsonschema_description:"Policy to compile the answer to the follow-up question"`
+}
+
+type GapResult struct {
+	ReasoningSteps []Step   `json:"reasoning_steps" jsonschema_description:"The chain of reasoning"`
+	Answered       bool     `json:"answered" jsonschema_description:"True if the findings answer the request"`
+	Missing        string   `json:"missing" jsonschema_description:"Information missing in the findings, empty if answered"`
+	Queries        []string `json:"queries" jsonschema_description:"Refined web search queries for the missing information, empty if answered"`
+}
+
+type Step struct {
+	Explanation string `json:"explanation"`
+	Output      string `json:"output"`
+}
+
+func GenerateSchema[T any]() interface{} {
+	// Structured Outputs uses a subset of JSON schema
+	// These flags are necessary to comply with the subset
+	reflector := jsonschema.Reflector{
+		AllowAdditionalProperties: false,
+		DoNotReference:            true,
+	}
+	var v T
+	schema := reflector.Reflect(v)
+	return schema
+}
@@ -0,0 +1,9 @@
+package llm
+
+// charsPerToken is the average number of characters per token of English text.
+const charsPerToken = 4
+
+// EstimateTokens returns a rough estimation of the number of tokens in the text.
+func EstimateTokens(text string) int64 {
+	return int64(len(text)+charsPerToken-1) / charsPerToken
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dimdasci/seek/internal/models"
+	"go.uber.org/zap"
+)
+
+// maxSourceChars limits the content of a single source in the verification prompt.
+const maxSourceChars = 24000
+
+// VerifyClaim checks the claim against the content of the source pages.
+// It returns the verdict and its explanation.
+func (c *Client) VerifyClaim(
+	ctx context.Context,
+	claim string,
+	pages []models.Page,
+) (models.Verdict, string, error) {
+	var sources strings.Builder
+	for _, page := range pages {
+		content := page.Content
+		if len(content) > maxSourceChars {
+			// cut before the rune crossing the limit
+			cut := maxSourceChars
+			for cut > 0 && !utf8.RuneStart(content[cut]) {
+				cut--
+			}
+			content = content[:cut]
+		}
+		fmt.Fprintf(&sources, "<source><title>%s<title><url>%s<url><content>%s<content><source>\n",
+			page.Title,
+			page.URL,
+			content)
+	}
+
+	prompt := fmt.Sprintf("%v\n\n"+
+		"<claim>%v<claim>\n\n"+
+		"<sources>%v<sources>",
+		verificationPrompt,
+		claim,
+		sources.String())
+
+	c.logger.Debug("Verification", zap.String("prompt", prompt))
+
+	// add timeout to the context
+	ctx, cancel := context.WithTimeout(ctx, c.completionTimeout)
+	defer cancel()
+
+	chat, err := c.complete(ctx, &Request{
+		Name:        "verify claim",
+		Tier:        TierCompletion,
+		Prompt:      prompt,
+		Schema:      c.verificationResultSchema,
+		Temperature: Float(0),
+	})
+	if err != nil {
+		c.logger.Error("failed to verify claim",
+			zap.Error(err),
+			zap.String("claim", claim))
+		return "", "", err
+	}
+
+	// Log completion stats
+	c.logger.Info("Verification",
+		zap.String("reason", chat.FinishReason),
+		zap.String("model", chat.Model),
+		zap.Int64("input tokens", chat.PromptTokens),
+		zap.Int64("completion tokens", chat.CompletionTokens),
+		zap.Int64("max tokens", chat.MaxTokens))
+
+	result := VerificationResult{}
+	if err := json.Unmarshal([]byte(chat.Content), &result); err != nil {
+		c.logger.Error("failed to unmarshal chat response",
+			zap.Error(err),
+			zap.String("completion", chat.Content))
+		return "", "", err
+	}
+
+	verdict := models.Verdict(result.Verdict)
+	switch verdict {
+	case models.VerdictSupported, models.VerdictUnsupported, models.VerdictContradicted:
+	default:
+		return "", "", fmt.Errorf("unknown verdict: %s", result.Verdict)
+	}
+
+	return verdict, result.Explanation, nil
+}
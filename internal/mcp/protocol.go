@@ -0,0 +1,109 @@
+package mcp
+
+import "encoding/json"
+
+// protocolVersions are the supported MCP versions, the latest first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700 // Message is not valid JSON
+	codeInvalidRequest = -32600 // Message is not a valid request
+	codeMethodNotFound = -32601 // Method is not supported
+	codeInvalidParams  = -32602 // Params of the method are invalid
+	codeInternalError  = -32603 // Server failed to handle the request
+)
+
+// message is an incoming JSON-RPC message, a request or a notification without ID.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response, with either the result or the error.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// notification is an outgoing JSON-RPC notification.
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// rpcError is the error of a JSON-RPC response.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// initializeParams are the params of the initialize request.
+type initializeParams struct {
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+// initializeResult is the result of the initialize request.
+type initializeResult struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities"`
+	ServerInfo      serverInfo     `json:"serverInfo"`
+	Instructions    string         `json:"instructions,omitempty"`
+}
+
+// serverInfo describes the server to the client.
+type serverInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// toolInfo describes a tool in the tools list.
+type toolInfo struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// listToolsResult is the result of the tools/list request.
+type listToolsResult struct {
+	Tools []toolInfo `json:"tools"`
+}
+
+// callToolParams are the params of the tools/call request.
+type callToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Meta      struct {
+		ProgressToken json.RawMessage `json:"progressToken,omitempty"`
+	} `json:"_meta"`
+}
+
+// content is a text content block of a tool result.
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// callToolResult is the result of the tools/call request.
+// Failures of a tool are reported in the result for the model to see.
+type callToolResult struct {
+	Content []content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+// progressParams are the params of the progress notification.
+type progressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      int             `json:"progress"`
+	Message       string          `json:"message,omitempty"`
+}
+
+// cancelledParams are the params of the cancelled notification.
+type cancelledParams struct {
+	RequestID json.RawMessage `json:"requestId"`
+}
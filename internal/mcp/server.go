@@ -0,0 +1,310 @@
+// Package mcp provides a Model Context Protocol server over stdio
+// exposing the web search, web reading and research of seek as tools.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Tool is a tool exposed to the clients.
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema string // JSON schema of the tool arguments
+	// Call runs the tool with the arguments and returns its text result.
+	// Long calls report their progress with the progress function.
+	Call func(ctx context.Context, args json.RawMessage, progress func(message string)) (string, error)
+}
+
+// Server is an MCP server reading newline-delimited JSON-RPC messages
+// and writing the responses and notifications to the output.
+type Server struct {
+	name    string
+	version string
+	tools   []Tool
+	logger  *zap.Logger
+
+	mu      sync.Mutex // Serializes the output messages
+	encoder *json.Encoder
+
+	callsMu sync.Mutex
+	calls   map[string]context.CancelFunc // Cancels tool calls in progress by request ID
+	wg      sync.WaitGroup                // Tool calls in progress
+}
+
+// NewServer creates a new MCP server with the name, version and tools.
+func NewServer(logger *zap.Logger, name string, version string, tools ...Tool) *Server {
+	return &Server{
+		name:    name,
+		version: version,
+		tools:   tools,
+		logger:  logger,
+		calls:   make(map[string]context.CancelFunc),
+	}
+}
+
+// Serve handles the messages from r and writes to w until r is closed or the context is done.
+// Tool calls run concurrently, the calls in progress are canceled and awaited before it returns.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.encoder = json.NewEncoder(w)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.wg.Wait()
+	}()
+
+	// messages are read in the background to stop on the context
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case line := <-lines:
+			s.handle(ctx, line)
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read message: %w", err)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// handle handles a single incoming message.
+func (s *Server) handle(ctx context.Context, line []byte) {
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		s.logger.Warn("Invalid MCP message", zap.Error(err))
+		s.respondError(nil, codeParseError, "invalid JSON message")
+		return
+	}
+	if msg.Method == "" {
+		// responses are not expected, the server sends no requests
+		if len(msg.ID) == 0 {
+			s.respondError(nil, codeInvalidRequest, "method is required")
+		}
+		return
+	}
+	if msg.JSONRPC != "2.0" {
+		s.respondError(msg.ID, codeInvalidRequest, "unsupported JSON-RPC version")
+		return
+	}
+
+	// notifications have no ID and get no response
+	if len(msg.ID) == 0 || string(msg.ID) == "null" {
+		s.handleNotification(msg)
+		return
+	}
+
+	s.logger.Debug("MCP request", zap.String("method", msg.Method))
+	switch msg.Method {
+	case "initialize":
+		s.initialize(msg)
+	case "ping":
+		s.respond(msg.ID, struct{}{})
+	case "tools/list":
+		s.listTools(msg)
+	case "tools/call":
+		s.callTool(ctx, msg)
+	default:
+		s.respondError(msg.ID, codeMethodNotFound, "method not found: "+msg.Method)
+	}
+}
+
+// handleNotification handles a notification from the client.
+func (s *Server) handleNotification(msg message) {
+	switch msg.Method {
+	case "notifications/cancelled":
+		var params cancelledParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return
+		}
+		s.callsMu.Lock()
+		cancel, ok := s.calls[string(params.RequestID)]
+		s.callsMu.Unlock()
+		if ok {
+			s.logger.Info("MCP tool call cancelled", zap.String("request", string(params.RequestID)))
+			cancel()
+		}
+	default:
+		s.logger.Debug("MCP notification", zap.String("method", msg.Method))
+	}
+}
+
+// initialize negotiates the protocol version and declares the server capabilities.
+func (s *Server) initialize(msg message) {
+	var params initializeParams
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.respondError(msg.ID, codeInvalidParams, "invalid initialize params")
+			return
+		}
+	}
+
+	version := protocolVersions[0]
+	if slices.Contains(protocolVersions, params.ProtocolVersion) {
+		version = params.ProtocolVersion
+	}
+
+	s.logger.Info("MCP session initialized",
+		zap.String("client_version", params.ProtocolVersion),
+		zap.String("version", version))
+	s.respond(msg.ID, initializeResult{
+		ProtocolVersion: version,
+		Capabilities:    map[string]any{"tools": map[string]any{}},
+		ServerInfo:      serverInfo{Name: s.name, Version: s.version},
+	})
+}
+
+// listTools returns the tools of the server with their input schemas.
+func (s *Server) listTools(msg message) {
+	result := listToolsResult{Tools: make([]toolInfo, 0, len(s.tools))}
+	for _, tool := range s.tools {
+		result.Tools = append(result.Tools, toolInfo{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: json.RawMessage(tool.InputSchema),
+		})
+	}
+	s.respond(msg.ID, result)
+}
+
+// callTool runs the tool in the background and responds with its result.
+// Progress notifications are sent if the client gave a progress token.
+// A panic of the tool is reported as a failed call instead of stopping the server.
+func (s *Server) callTool(ctx context.Context, msg message) {
+	var params callToolParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.respondError(msg.ID, codeInvalidParams, "invalid tool call params")
+		return
+	}
+	i := slices.IndexFunc(s.tools, func(tool Tool) bool { return tool.Name == params.Name })
+	if i < 0 {
+		s.respondError(msg.ID, codeInvalidParams, "unknown tool: "+params.Name)
+		return
+	}
+	tool := s.tools[i]
+
+	ctx, cancel := context.WithCancel(ctx)
+	id := string(msg.ID)
+	s.callsMu.Lock()
+	s.calls[id] = cancel
+	s.callsMu.Unlock()
+
+	var progress func(message string)
+	if token := params.Meta.ProgressToken; len(token) > 0 {
+		var mu sync.Mutex
+		step := 0
+		progress = func(message string) {
+			mu.Lock()
+			defer mu.Unlock()
+			step++
+			s.notify("notifications/progress", progressParams{
+				ProgressToken: token,
+				Progress:      step,
+				Message:       message,
+			})
+		}
+	} else {
+		progress = func(string) {}
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.callsMu.Lock()
+			delete(s.calls, id)
+			s.callsMu.Unlock()
+			cancel()
+		}()
+
+		s.logger.Info("MCP tool call", zap.String("tool", tool.Name))
+		text, err := s.call(ctx, tool, params.Arguments, progress)
+		if err != nil {
+			s.logger.Error("MCP tool call failed", zap.String("tool", tool.Name), zap.Error(err))
+			text = strings.TrimSpace(text + "\n\n" + err.Error())
+		}
+		// a cancelled request gets no response
+		if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			return
+		}
+		s.respond(msg.ID, callToolResult{
+			Content: []content{{Type: "text", Text: text}},
+			IsError: err != nil,
+		})
+	}()
+}
+
+// call runs the tool and returns the panic of the tool as an error.
+func (s *Server) call(ctx context.Context, tool Tool, args json.RawMessage, progress func(message string)) (text string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("MCP tool call panicked",
+				zap.String("tool", tool.Name),
+				zap.Any("panic", r),
+				zap.Stack("stack"))
+			text, err = "", fmt.Errorf("internal error: %v", r)
+		}
+	}()
+	return tool.Call(ctx, args, progress)
+}
+
+// respond writes the result of the request.
+func (s *Server) respond(id json.RawMessage, result any) {
+	s.write(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+// respondError writes the error of the request, a nil ID is sent as null.
+func (s *Server) respondError(id json.RawMessage, code int, message string) {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	s.write(response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}})
+}
+
+// notify writes the notification.
+func (s *Server) notify(method string, params any) {
+	s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// write writes the message as a line of JSON.
+func (s *Server) write(v any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.encoder.Encode(v); err != nil {
+		s.logger.Error("Failed to write MCP message", zap.Error(err))
+	}
+}
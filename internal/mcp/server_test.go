@@ -0,0 +1,167 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// serve runs the server on the input lines and returns the output messages.
+func serve(t *testing.T, lines ...string) []map[string]any {
+	t.Helper()
+
+	server := NewServer(zap.NewNop(), "seek", "test",
+		Tool{
+			Name:        "echo",
+			InputSchema: `{"type": "object"}`,
+			Call: func(ctx context.Context, args json.RawMessage, progress func(string)) (string, error) {
+				progress("echoing")
+				return string(args), nil
+			},
+		},
+		Tool{
+			Name:        "fail",
+			InputSchema: `{"type": "object"}`,
+			Call: func(ctx context.Context, args json.RawMessage, progress func(string)) (string, error) {
+				return "", errors.New("tool failed")
+			},
+		},
+		Tool{
+			Name:        "panic",
+			InputSchema: `{"type": "object"}`,
+			Call: func(ctx context.Context, args json.RawMessage, progress func(string)) (string, error) {
+				panic("tool panicked")
+			},
+		},
+	)
+
+	var out bytes.Buffer
+	input := strings.NewReader(strings.Join(lines, "\n") + "\n")
+	if err := server.Serve(context.Background(), input, &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	var messages []map[string]any
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var msg map[string]any
+		if err := decoder.Decode(&msg); err != nil {
+			t.Fatalf("invalid output message: %v", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestServerDispatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		code    float64 // JSON-RPC error code, 0 for a result
+		result  string  // substring of the JSON result
+	}{
+		{
+			name:    "initialize with a supported version",
+			request: `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+			result:  `"protocolVersion":"2025-03-26"`,
+		},
+		{
+			name:    "initialize with an unknown version",
+			request: `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+			result:  `"protocolVersion":"` + protocolVersions[0] + `"`,
+		},
+		{
+			name:    "ping",
+			request: `{"jsonrpc":"2.0","id":1,"method":"ping"}`,
+			result:  `{}`,
+		},
+		{
+			name:    "tools list",
+			request: `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+			result:  `"name":"echo"`,
+		},
+		{
+			name:    "tool call",
+			request: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"q":"x"}}}`,
+			result:  `"text":"{\"q\":\"x\"}"`,
+		},
+		{
+			name:    "failed tool call",
+			request: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fail"}}`,
+			result:  `"isError":true`,
+		},
+		{
+			name:    "panicking tool call",
+			request: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"panic"}}`,
+			result:  `"isError":true`,
+		},
+		{
+			name:    "unknown tool",
+			request: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"missing"}}`,
+			code:    codeInvalidParams,
+		},
+		{
+			name:    "unknown method",
+			request: `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+			code:    codeMethodNotFound,
+		},
+		{
+			name:    "invalid JSON",
+			request: `{"jsonrpc":`,
+			code:    codeParseError,
+		},
+		{
+			name:    "unsupported version",
+			request: `{"jsonrpc":"1.0","id":1,"method":"ping"}`,
+			code:    codeInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := serve(t, tt.request)
+			if len(messages) != 1 {
+				t.Fatalf("got %d messages, want 1: %v", len(messages), messages)
+			}
+			msg := messages[0]
+
+			if tt.code != 0 {
+				rpcErr, ok := msg["error"].(map[string]any)
+				if !ok || rpcErr["code"] != tt.code {
+					t.Errorf("response = %v, want error code %v", msg, tt.code)
+				}
+				return
+			}
+			result, err := json.Marshal(msg["result"])
+			if err != nil || !strings.Contains(string(result), tt.result) {
+				t.Errorf("result = %s, want it to contain %s", result, tt.result)
+			}
+		})
+	}
+}
+
+func TestServerNotifications(t *testing.T) {
+	messages := serve(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	if len(messages) != 0 {
+		t.Errorf("notification got %d responses, want none", len(messages))
+	}
+}
+
+func TestServerProgress(t *testing.T) {
+	messages := serve(t,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"echo","_meta":{"progressToken":"p1"}}}`)
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want a progress notification and a response", len(messages))
+	}
+	if messages[0]["method"] != "notifications/progress" {
+		t.Errorf("first message = %v, want a progress notification", messages[0])
+	}
+	if messages[1]["id"] != float64(7) {
+		t.Errorf("second message = %v, want the response to request 7", messages[1])
+	}
+}
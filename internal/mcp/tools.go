@@ -0,0 +1,224 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dimdasci/seek/internal/progress"
+	"github.com/dimdasci/seek/internal/service/search"
+	"github.com/dimdasci/seek/internal/service/webread"
+	"github.com/dimdasci/seek/internal/service/websearch"
+	"github.com/dimdasci/seek/internal/session"
+	"github.com/dimdasci/seek/internal/usage"
+	"go.uber.org/zap"
+)
+
+// maxReadURLs is the max number of URLs read by a single tool call.
+const maxReadURLs = 20
+
+// SearchFactory creates the search service of a research call. The service sends
+// the progress of the call to the observer and checks the report claims if verify is set.
+// It returns the service and the meter accounting the call usage, nil if not accounted.
+type SearchFactory func(observer progress.Observer, verify bool) (*search.Service, *usage.Meter, error)
+
+const webSearchSchema = `{
+  "type": "object",
+  "properties": {
+    "query": {"type": "string", "description": "Search query"},
+    "max_results": {"type": "integer", "minimum": 1, "description": "Max number of results, all results by default"}
+  },
+  "required": ["query"],
+  "additionalProperties": false
+}`
+
+const readURLsSchema = `{
+  "type": "object",
+  "properties": {
+    "urls": {
+      "type": "array",
+      "items": {"type": "string", "format": "uri"},
+      "minItems": 1,
+      "maxItems": 20,
+      "description": "URLs of the web pages to read"
+    }
+  },
+  "required": ["urls"],
+  "additionalProperties": false
+}`
+
+const researchSchema = `{
+  "type": "object",
+  "properties": {
+    "question": {"type": "string", "description": "Question or information request to research"},
+    "verify": {"type": "boolean", "description": "Check the report claims against the cited pages", "default": false}
+  },
+  "required": ["question"],
+  "additionalProperties": false
+}`
+
+// NewWebSearchTool creates the web_search tool returning the results of the searcher.
+func NewWebSearchTool(searcher websearch.WebSearcher) Tool {
+	return Tool{
+		Name:        "web_search",
+		Description: "Search the web and return the titles, URLs and snippets of the results.",
+		InputSchema: webSearchSchema,
+		Call: func(ctx context.Context, args json.RawMessage, _ func(string)) (string, error) {
+			var params struct {
+				Query      string `json:"query"`
+				MaxResults int    `json:"max_results"`
+			}
+			if err := decodeArgs(args, &params); err != nil {
+				return "", err
+			}
+			if strings.TrimSpace(params.Query) == "" {
+				return "", fmt.Errorf("query is required")
+			}
+
+			results, err := searcher.Search(ctx, params.Query)
+			if err != nil {
+				return "", fmt.Errorf("failed to search the web: %w", err)
+			}
+			if params.MaxResults > 0 && len(results) > params.MaxResults {
+				results = results[:params.MaxResults]
+			}
+			if len(results) == 0 {
+				return "No results found.", nil
+			}
+
+			var b strings.Builder
+			for _, result := range results {
+				b.WriteString(result.String())
+			}
+			return strings.TrimSpace(b.String()), nil
+		},
+	}
+}
+
+// NewReadURLsTool creates the read_urls tool returning the pages read by the reader in markdown.
+func NewReadURLsTool(reader webread.WebReader) Tool {
+	return Tool{
+		Name:        "read_urls",
+		Description: "Read web pages, rendering JavaScript when needed, and return their content in markdown.",
+		InputSchema: readURLsSchema,
+		Call: func(ctx context.Context, args json.RawMessage, _ func(string)) (string, error) {
+			var params struct {
+				URLs []string `json:"urls"`
+			}
+			if err := decodeArgs(args, &params); err != nil {
+				return "", err
+			}
+			if len(params.URLs) == 0 {
+				return "", fmt.Errorf("urls are required")
+			}
+			if len(params.URLs) > maxReadURLs {
+				return "", fmt.Errorf("at most %d urls can be read at once", maxReadURLs)
+			}
+
+			pages, err := reader.Read(ctx, params.URLs)
+			if err != nil {
+				return "", fmt.Errorf("failed to read web pages: %w", err)
+			}
+
+			parts := make([]string, 0, len(pages.Pages)+len(pages.Errors))
+			for _, page := range pages.Pages {
+				parts = append(parts, fmt.Sprintf("# %s\n\nURL: %s\n\n%s",
+					page.Title, page.URL, strings.TrimSpace(page.Content)))
+			}
+			for _, pageErr := range pages.Errors {
+				parts = append(parts, fmt.Sprintf("Failed to read %s: %s", pageErr.URL, pageErr.Error))
+			}
+			text := strings.Join(parts, "\n\n---\n\n")
+			if len(pages.Pages) == 0 {
+				return text, fmt.Errorf("no page could be read")
+			}
+			return text, nil
+		},
+	}
+}
+
+// NewResearchTool creates the research tool answering a question with a full search run.
+// Every call is saved as a session in the store and reports the run progress.
+// At most maxRuns calls run at once, the others wait; 0 runs all calls at once.
+func NewResearchTool(store *session.Store, newSearch SearchFactory, maxRuns int, logger *zap.Logger) Tool {
+	var runs chan struct{}
+	if maxRuns > 0 {
+		runs = make(chan struct{}, maxRuns)
+	}
+	return Tool{
+		Name: "research",
+		Description: "Research a question on the web: plan the search, read and analyse the pages " +
+			"and write a markdown report citing the sources. Takes minutes, reports its progress.",
+		InputSchema: researchSchema,
+		Call: func(ctx context.Context, args json.RawMessage, report func(string)) (string, error) {
+			var params struct {
+				Question string `json:"question"`
+				Verify   bool   `json:"verify"`
+			}
+			if err := decodeArgs(args, &params); err != nil {
+				return "", err
+			}
+			if strings.TrimSpace(params.Question) == "" {
+				return "", fmt.Errorf("question is required")
+			}
+
+			if runs != nil {
+				select {
+				case runs <- struct{}{}:
+				default:
+					report("Waiting for a running research to finish...")
+					select {
+					case runs <- struct{}{}:
+					case <-ctx.Done():
+						return "", ctx.Err()
+					}
+				}
+				defer func() { <-runs }()
+			}
+
+			searchService, meter, err := newSearch(progressFunc(report), params.Verify)
+			if err != nil {
+				return "", fmt.Errorf("failed to create search service: %w", err)
+			}
+			sess, err := store.Create(params.Question)
+			if err != nil {
+				return "", fmt.Errorf("failed to create session: %w", err)
+			}
+			report(fmt.Sprintf("Session %s", sess.ID))
+
+			result, err := searchService.Search(ctx, sess, nil)
+			logger.Info("Research finished",
+				zap.String("session", sess.ID),
+				zap.String("usage", strings.TrimSpace(meter.Summary())),
+				zap.Error(err))
+			if err != nil {
+				return "", fmt.Errorf("%w, resume with: seek answer --resume %s", err, sess.ID)
+			}
+			return result.Report, nil
+		},
+	}
+}
+
+// progressFunc reports the messages of the progress events to a tool call.
+type progressFunc func(message string)
+
+// Observe reports the message of the event.
+func (f progressFunc) Observe(event progress.Event) {
+	if event.Message != "" {
+		f(event.Message)
+	}
+}
+
+// decodeArgs decodes the tool arguments into v rejecting unknown arguments.
+func decodeArgs(args json.RawMessage, v any) error {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(args)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package models
+
+// FollowUpPlan represents the decision how to answer a follow-up question
+// of a completed research.
+type FollowUpPlan struct {
+	Covered           bool     `json:"covered"`            // Findings of the research cover the follow-up question
+	Reason            string   `json:"reason"`             // Reason of the decision
+	SearchQueries     []string `json:"search_queries"`     // Web search queries for the missing information
+	CompilationPolicy string   `json:"compilation_policy"` // Policy to compile the answer
+}
@@ -0,0 +1,8 @@
+package models
+
+// Gap represents the assessment of the findings compiled on a topic.
+type Gap struct {
+	Answered bool     `json:"answered"`          // Findings answer the request of the topic
+	Missing  string   `json:"missing,omitempty"` // Information missing in the findings
+	Queries  []string `json:"queries,omitempty"` // Refined web search queries for the missing information
+}
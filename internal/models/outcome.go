@@ -0,0 +1,39 @@
+package models
+
+// StepStatus is the status of a research step at the end of a run.
+type StepStatus string
+
+const (
+	StepCompleted StepStatus = "completed" // Findings of the step are compiled
+	StepPartial   StepStatus = "partial"   // Output of the step is incomplete
+	StepFailed    StepStatus = "failed"    // Step failed with an error
+	StepSkipped   StepStatus = "skipped"   // Step is not run to stay within the budget
+)
+
+// FailureReason classifies the failure of a research step.
+type FailureReason string
+
+const (
+	ReasonSearchFailed   FailureReason = "search_failed"   // Web search failed
+	ReasonPagesFailed    FailureReason = "pages_failed"    // No page could be read
+	ReasonAnalysisFailed FailureReason = "analysis_failed" // No page could be analysed
+	ReasonRefused        FailureReason = "refused"         // Model refused the request
+	ReasonTruncated      FailureReason = "truncated"       // Model output is cut by the token limit
+	ReasonNoFindings     FailureReason = "no_findings"     // Nothing to compile the step from
+	ReasonBudget         FailureReason = "budget"          // Budget of the run is running out
+	ReasonError          FailureReason = "error"           // Any other error
+)
+
+// StepOutcome represents the result of a research step.
+type StepOutcome struct {
+	ID     string        `json:"id"`               // Step ID
+	Topic  string        `json:"topic"`            // Step topic
+	Status StepStatus    `json:"status"`           // Step status
+	Reason FailureReason `json:"reason,omitempty"` // Failure class, empty for completed steps
+	Error  string        `json:"error,omitempty"`  // Failure message, empty for completed steps
+}
+
+// Completed reports whether the step is completed in full.
+func (o StepOutcome) Completed() bool {
+	return o.Status == StepCompleted
+}
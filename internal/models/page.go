@@ -2,11 +2,19 @@ package models
 
 // Page represents a web page content.
 type Page struct {
+	ID      string `json:"id,omitempty"` // Source ID assigned by the search run
 	URL     string `json:"url"`
 	Title   string `json:"title,omitempty"`
 	Content string `json:"raw_content"`
 }
 
+// Source represents a web page cited in a report.
+type Source struct {
+	ID    string `json:"id"`
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
 // PageError represents a web page error.
 type PageError struct {
 	URL   string `json:"url"`
@@ -18,3 +26,10 @@ type WebPages struct {
 	Pages  []Page      `json:"results"`
 	Errors []PageError `json:"failed_results"`
 }
+
+// PageAnalysis represents the relevance of a web page to a request and its key points.
+type PageAnalysis struct {
+	URL       string `json:"url"`
+	Relevant  bool   `json:"relevant"`
+	KeyPoints string `json:"key_points,omitempty"`
+}
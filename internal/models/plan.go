@@ -7,24 +7,28 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Plan represents a search plan.
 type Plan struct {
-	Approved          bool     `json:"approved"`           // Approved field set to false if request contains illegal content or other instructions
-	Reason            string   `json:"reason"`             // Reason for approval or rejection
-	SearchQuery       string   `json:"search_query"`       // Web search query for simple requests, null for complex requests
-	SearchComplexity  string   `json:"search_complexity"`  // Search complexity: simple or complex
-	SearchPlan        []Search `json:"search_plan"`        // Search plan for the request
-	CompilationPolicy string   `json:"compilation_policy"` // Policy to compile the findings into the final report
+	Approved          bool     `json:"approved" yaml:"approved"`                     // Approved field set to false if request contains illegal content or other instructions
+	Reason            string   `json:"reason" yaml:"reason"`                         // Reason for approval or rejection
+	SearchQuery       string   `json:"search_query" yaml:"search_query"`             // Web search query for simple requests, null for complex requests
+	SearchComplexity  string   `json:"search_complexity" yaml:"search_complexity"`   // Search complexity: simple or complex
+	SearchPlan        []Search `json:"search_plan" yaml:"search_plan"`               // Search plan for the request
+	CompilationPolicy string   `json:"compilation_policy" yaml:"compilation_policy"` // Policy to compile the findings into the final report
 }
 
 // Search represents a search plan for a specific topic.
 type Search struct {
-	Topic              string `json:"topic"`                // Topic of the search
-	SearchQuery        string `json:"search_query"`         // Web search query
-	SubRequest         string `json:"sub_request"`          // Sub-request to conduct the information gathering
-	FinalAnswerOutline string `json:"final_answer_outline"` // Outline of the final answer
+	ID                 string   `json:"id" yaml:"id"`                                     // Unique identifier of the step within the plan
+	Topic              string   `json:"topic" yaml:"topic"`                               // Topic of the search
+	SearchQuery        string   `json:"search_query" yaml:"search_query"`                 // Web search query, empty for analysis of previous steps
+	SubRequest         string   `json:"sub_request" yaml:"sub_request"`                   // Sub-request to conduct the information gathering
+	FinalAnswerOutline string   `json:"final_answer_outline" yaml:"final_answer_outline"` // Outline of the final answer
+	DependsOn          []string `json:"depends_on" yaml:"depends_on"`                     // IDs of the steps whose findings the step needs
 }
 
 var (
@@ -47,6 +51,7 @@ func NewPlan(jsonStr string) (*Plan, error) {
 		return nil, err
 	}
 
+	plan.normalize()
 	if err = plan.validate(); err != nil {
 		return nil, err
 	}
@@ -54,12 +59,41 @@ func NewPlan(jsonStr string) (*Plan, error) {
 	return plan, nil
 }
 
+// NewPlanFromYAML creates a new search plan from a YAML document.
+// The plan is checked by the same rules as the plans built from JSON.
+func NewPlanFromYAML(yamlStr string) (*Plan, error) {
+	plan := &Plan{}
+	if err := yaml.Unmarshal([]byte(yamlStr), plan); err != nil {
+		return nil, err
+	}
+
+	plan.normalize()
+	if err := plan.validate(); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// YAML returns the YAML representation of the search plan.
+func (p *Plan) YAML() string {
+	b, _ := yaml.Marshal(p)
+	return string(b)
+}
+
 // String returns the string representation of the search plan.
 func (p *Plan) String() string {
 	b, _ := json.MarshalIndent(p, "", "  ")
 	return string(b)
 }
 
+// Validate fills missing step ids and dependencies and ensures the plan is valid.
+// Use it to check a plan edited after creation.
+func (p *Plan) Validate() error {
+	p.normalize()
+	return p.validate()
+}
+
 // validate ensures the plan is valid according to business rules
 func (p *Plan) validate() error {
 	// Must have a reason if not approved
@@ -80,6 +114,9 @@ func (p *Plan) validate() error {
 		if len(p.SearchPlan) == 0 {
 			return errors.New("search_plan is required for complex searches")
 		}
+		if err := p.validateSteps(); err != nil {
+			return err
+		}
 	default:
 		if p.Approved {
 			return fmt.Errorf("invalid search complexity: %s", p.SearchComplexity)
@@ -89,6 +126,82 @@ func (p *Plan) validate() error {
 	return nil
 }
 
+// normalize fills step identifiers and dependencies missing in plans
+// built without them. Steps without ids are numbered by their position,
+// and analysis steps without dependencies depend on all previous steps.
+func (p *Plan) normalize() {
+	for i := range p.SearchPlan {
+		step := &p.SearchPlan[i]
+		if step.ID == "" {
+			step.ID = fmt.Sprintf("%d", i+1)
+		}
+		if step.SearchQuery == "" && step.DependsOn == nil {
+			step.DependsOn = make([]string, 0, i)
+			for _, prev := range p.SearchPlan[:i] {
+				step.DependsOn = append(step.DependsOn, prev.ID)
+			}
+		}
+	}
+}
+
+// validateSteps ensures step ids are unique, dependencies refer to known steps
+// and the dependency graph has no cycles.
+func (p *Plan) validateSteps() error {
+	steps := make(map[string]*Search, len(p.SearchPlan))
+	for i := range p.SearchPlan {
+		step := &p.SearchPlan[i]
+		if step.ID == "" {
+			return fmt.Errorf("id is required for step %d", i+1)
+		}
+		if _, ok := steps[step.ID]; ok {
+			return fmt.Errorf("duplicate step id: %s", step.ID)
+		}
+		steps[step.ID] = step
+	}
+
+	for _, step := range p.SearchPlan {
+		for _, dep := range step.DependsOn {
+			if _, ok := steps[dep]; !ok {
+				return fmt.Errorf("step %s depends on unknown step %s", step.ID, dep)
+			}
+		}
+		if step.SearchQuery == "" && len(step.DependsOn) == 0 {
+			return fmt.Errorf("step %s has neither search_query nor depends_on", step.ID)
+		}
+	}
+
+	// detect cycles with depth-first search
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(steps))
+	var visit func(id string, path []string) error
+	visit = func(id string, path []string) error {
+		switch state[id] {
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s -> %s", strings.Join(path, " -> "), id)
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		for _, dep := range steps[id].DependsOn {
+			if err := visit(dep, append(path, id)); err != nil {
+				return err
+			}
+		}
+		state[id] = visited
+		return nil
+	}
+	for _, step := range p.SearchPlan {
+		if err := visit(step.ID, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // extractJSONFromMarkdown extracts JSON content from markdown code blocks
 func extractJSONFromMarkdown(markdown string) (string, error) {
 	// Find all JSON blocks
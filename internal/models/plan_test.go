@@ -0,0 +1,114 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPlanValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []Search
+		err   string // substring of the expected error, empty for a valid plan
+	}{
+		{
+			name: "independent steps",
+			steps: []Search{
+				{ID: "a", SearchQuery: "q1"},
+				{ID: "b", SearchQuery: "q2"},
+			},
+		},
+		{
+			name: "analysis step depends on searches",
+			steps: []Search{
+				{ID: "a", SearchQuery: "q1"},
+				{ID: "b", SearchQuery: "q2"},
+				{ID: "c", DependsOn: []string{"a", "b"}},
+			},
+		},
+		{
+			name: "duplicate id",
+			steps: []Search{
+				{ID: "a", SearchQuery: "q1"},
+				{ID: "a", SearchQuery: "q2"},
+			},
+			err: "duplicate step id: a",
+		},
+		{
+			name: "unknown dependency",
+			steps: []Search{
+				{ID: "a", SearchQuery: "q1", DependsOn: []string{"x"}},
+			},
+			err: "step a depends on unknown step x",
+		},
+		{
+			name: "self dependency",
+			steps: []Search{
+				{ID: "a", SearchQuery: "q1", DependsOn: []string{"a"}},
+			},
+			err: "dependency cycle: a -> a",
+		},
+		{
+			name: "cycle",
+			steps: []Search{
+				{ID: "a", SearchQuery: "q1", DependsOn: []string{"c"}},
+				{ID: "b", SearchQuery: "q2", DependsOn: []string{"a"}},
+				{ID: "c", SearchQuery: "q3", DependsOn: []string{"b"}},
+			},
+			err: "dependency cycle: a -> c -> b -> a",
+		},
+		{
+			name: "analysis step without dependencies",
+			steps: []Search{
+				{ID: "a", DependsOn: []string{}},
+			},
+			err: "step a has neither search_query nor depends_on",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &Plan{
+				Approved:         true,
+				SearchComplexity: "complex",
+				SearchPlan:       tt.steps,
+			}
+			err := plan.Validate()
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("Validate() error = %v, want nil", err)
+			case tt.err != "" && err == nil:
+				t.Fatalf("Validate() error = nil, want %q", tt.err)
+			case tt.err != "" && !strings.Contains(err.Error(), tt.err):
+				t.Fatalf("Validate() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPlanNormalize(t *testing.T) {
+	plan := &Plan{
+		Approved:         true,
+		SearchComplexity: "complex",
+		SearchPlan: []Search{
+			{SearchQuery: "q1"},
+			{SearchQuery: "q2"},
+			{Topic: "analysis"},
+		},
+	}
+	if err := plan.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	ids := make([]string, 0, len(plan.SearchPlan))
+	for _, step := range plan.SearchPlan {
+		ids = append(ids, step.ID)
+	}
+	if want := []string{"1", "2", "3"}; !slices.Equal(ids, want) {
+		t.Errorf("step ids = %v, want %v", ids, want)
+	}
+	if want := []string{"1", "2"}; !slices.Equal(plan.SearchPlan[2].DependsOn, want) {
+		t.Errorf("analysis step depends on %v, want %v", plan.SearchPlan[2].DependsOn, want)
+	}
+}
@@ -2,11 +2,12 @@ package models
 
 import "fmt"
 
-// SearchResult represents a single search result from Tavily API.
+// SearchResult represents a single search result from a web search provider.
 type SearchResult struct {
-	Title   string `json:"title"`
-	URL     string `json:"url"`
-	Content string `json:"content"`
+	Title     string   `json:"title"`
+	URL       string   `json:"url"`
+	Content   string   `json:"content"`
+	Providers []string `json:"providers,omitempty"` // Providers returned the result, set by fusion search
 }
 
 // String returns the string representation of the search result.
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verdict represents the result of a claim check against its sources.
+type Verdict string
+
+const (
+	VerdictSupported    Verdict = "supported"    // Sources confirm the claim
+	VerdictUnsupported  Verdict = "unsupported"  // Sources do not mention the claim
+	VerdictContradicted Verdict = "contradicted" // Sources state the opposite
+	VerdictUnchecked    Verdict = "unchecked"    // Claim could not be checked, the sources or the model failed
+)
+
+// Claim represents a single statement of a report checked against its sources.
+type Claim struct {
+	Text        string   `json:"text"`                  // Text of the claim without citation markers
+	Sources     []string `json:"sources"`               // URLs of the sources cited by the claim
+	Verdict     Verdict  `json:"verdict"`               // Verdict of the check
+	Explanation string   `json:"explanation,omitempty"` // Explanation of the verdict
+}
+
+// Verification represents the results of the report claims check.
+type Verification struct {
+	Claims []Claim `json:"claims"`
+}
+
+// Count returns the number of claims with the verdict.
+func (v *Verification) Count(verdict Verdict) int {
+	n := 0
+	for _, c := range v.Claims {
+		if c.Verdict == verdict {
+			n++
+		}
+	}
+	return n
+}
+
+// Markdown returns the verification results as a report section.
+// Supported claims are counted, the unsupported and contradicted ones are listed
+// with explanations, and the claims that could not be checked are listed apart.
+func (v *Verification) Markdown() string {
+	unchecked := v.Count(VerdictUnchecked)
+
+	var b strings.Builder
+	b.WriteString("## Verification\n\n")
+	fmt.Fprintf(&b, "%d claims checked against the cited sources: %d supported, %d unsupported, %d contradicted.\n",
+		len(v.Claims)-unchecked,
+		v.Count(VerdictSupported),
+		v.Count(VerdictUnsupported),
+		v.Count(VerdictContradicted))
+
+	for _, c := range v.Claims {
+		if c.Verdict == VerdictSupported || c.Verdict == VerdictUnchecked {
+			continue
+		}
+		writeClaim(&b, c)
+	}
+
+	if unchecked > 0 {
+		fmt.Fprintf(&b, "\n%d claims could not be checked:\n", unchecked)
+		for _, c := range v.Claims {
+			if c.Verdict == VerdictUnchecked {
+				writeClaim(&b, c)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// writeClaim writes the claim with its verdict and explanation as a list item.
+func writeClaim(b *strings.Builder, c Claim) {
+	fmt.Fprintf(b, "\n- **%s**: %s\n", c.Verdict, c.Text)
+	if c.Explanation != "" {
+		fmt.Fprintf(b, "  %s\n", c.Explanation)
+	}
+}
@@ -0,0 +1,53 @@
+// Package progress provides the progress events of a research run
+// and their renderers for the terminal and machine consumers.
+package progress
+
+import (
+	"time"
+
+	"github.com/dimdasci/seek/internal/models"
+)
+
+// Type is the type of a progress event.
+type Type string
+
+const (
+	Planning      Type = "planning"       // Search plan is being built
+	PlanBuilt     Type = "plan_built"     // Search plan is ready
+	StepStarted   Type = "step_started"   // Step of the plan has started
+	StepRestored  Type = "step_restored"  // Step is restored from the session
+	StepSkipped   Type = "step_skipped"   // Step is skipped to stay within the budget
+	StepDone      Type = "step_done"      // Step has finished, see the status
+	Deepening     Type = "deepening"      // Refined queries are searched for the missing information
+	PagesFetched  Type = "pages_fetched"  // Pages of the search results are read
+	PageAnalysed  Type = "page_analysed"  // Page is analysed by the model
+	ReportStarted Type = "report_started" // Final report is being written
+	ReportDone    Type = "report_done"    // Final report is written
+	Verifying     Type = "verifying"      // Claims of the report are being checked
+	VerifySkipped Type = "verify_skipped" // Claim check is skipped to stay within the budget
+)
+
+// Event represents a progress event of a research run.
+// Fields not related to the event type are empty.
+type Event struct {
+	Type     Type              `json:"type"`
+	Time     time.Time         `json:"time"`
+	Step     int               `json:"step,omitempty"`     // Position of the step in the plan, from 1
+	StepID   string            `json:"step_id,omitempty"`  // Session step ID
+	Topic    string            `json:"topic,omitempty"`    // Step topic
+	Query    string            `json:"query,omitempty"`    // Search query
+	URL      string            `json:"url,omitempty"`      // Page URL
+	Steps    int               `json:"steps,omitempty"`    // Number of steps in the plan
+	Pages    int               `json:"pages,omitempty"`    // Number of pages read
+	Failed   int               `json:"failed,omitempty"`   // Number of pages failed to read
+	Relevant bool              `json:"relevant,omitempty"` // Page is relevant to the step
+	Status   models.StepStatus `json:"status,omitempty"`   // Step status
+	Error    string            `json:"error,omitempty"`    // Failure message
+	Message  string            `json:"message"`            // Human readable description
+}
+
+// Observer receives the progress events of a research run.
+// Events can be delivered from several goroutines at once.
+type Observer interface {
+	Observe(event Event)
+}
@@ -0,0 +1,26 @@
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// JSON renders the events as JSON lines.
+type JSON struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSON creates a new JSON lines renderer writing to w.
+func NewJSON(w io.Writer) *JSON {
+	return &JSON{encoder: json.NewEncoder(w)}
+}
+
+// Observe writes the event as a JSON line.
+func (j *JSON) Observe(event Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.encoder.Encode(event)
+}
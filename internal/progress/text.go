@@ -0,0 +1,124 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are the frames of the terminal spinner.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinnerInterval is the time between the spinner frames.
+const spinnerInterval = 100 * time.Millisecond
+
+// Text renders the events as lines of text. With the spinner the current
+// activity and the pages read and analysed are shown in a line updated in place,
+// the spinner is paused while the final report is streamed.
+type Text struct {
+	mu       sync.Mutex
+	w        io.Writer
+	spinner  bool
+	status   string // Last line printed, shown by the spinner
+	pages    int    // Pages read in the run
+	analysed int    // Pages analysed in the run
+	frame    int    // Current spinner frame
+	paused   bool   // Spinner is hidden
+	shown    bool   // Spinner line is on the screen
+	stop     chan struct{}
+	stopped  sync.WaitGroup
+	close    sync.Once
+}
+
+// NewText creates a new text renderer writing to w.
+// The spinner is meant for terminals, the renderer must be closed to stop it.
+func NewText(w io.Writer, spinner bool) *Text {
+	t := &Text{
+		w:       w,
+		spinner: spinner,
+		stop:    make(chan struct{}),
+	}
+	if spinner {
+		t.stopped.Add(1)
+		go t.spin()
+	}
+	return t
+}
+
+// Observe renders the event.
+func (t *Text) Observe(event Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch event.Type {
+	case PagesFetched:
+		t.pages += event.Pages
+	case PageAnalysed:
+		t.analysed++
+	default:
+		t.clear()
+		fmt.Fprintln(t.w, event.Message)
+		t.status = event.Message
+	}
+
+	switch event.Type {
+	case ReportStarted:
+		t.paused = true
+	case ReportDone:
+		t.paused = false
+	}
+	t.draw()
+}
+
+// Close stops the spinner and clears its line. It can be called more than once.
+func (t *Text) Close() {
+	t.close.Do(func() {
+		close(t.stop)
+		t.stopped.Wait()
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.paused = true
+		t.clear()
+	})
+}
+
+// spin advances the spinner frame until the renderer is closed.
+func (t *Text) spin() {
+	defer t.stopped.Done()
+
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.mu.Lock()
+			t.frame = (t.frame + 1) % len(spinnerFrames)
+			t.draw()
+			t.mu.Unlock()
+		}
+	}
+}
+
+// draw shows the spinner line. The caller must hold the lock.
+func (t *Text) draw() {
+	if !t.spinner || t.paused || t.status == "" {
+		return
+	}
+	fmt.Fprintf(t.w, "\r\033[K%s %s", spinnerFrames[t.frame], t.status)
+	if t.pages > 0 {
+		fmt.Fprintf(t.w, " (%d pages read, %d analysed)", t.pages, t.analysed)
+	}
+	t.shown = true
+}
+
+// clear removes the spinner line. The caller must hold the lock.
+func (t *Text) clear() {
+	if t.shown {
+		fmt.Fprint(t.w, "\r\033[K")
+		t.shown = false
+	}
+}
@@ -0,0 +1,164 @@
+// Package retry provides the retry policy of the outbound HTTP requests
+// with capped exponential backoff, jitter and Retry-After handling.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Policy sets how failed requests are retried.
+type Policy struct {
+	MaxAttempts  int           // Max attempts including the first one, 1 or less disables retries
+	InitialDelay time.Duration // Delay before the first retry, doubled for every next one
+	MaxDelay     time.Duration // Max delay between attempts, longer Retry-After stops retries
+}
+
+// Retryable reports whether a response with the status code is worth retrying.
+func Retryable(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Transport is an HTTP transport retrying the requests failed with a transport
+// error or a retryable status by the policy. Requests with a body are retried
+// only if the body can be recreated. Retries stop at the request context deadline.
+type Transport struct {
+	base   http.RoundTripper
+	policy Policy
+	logger *zap.Logger
+}
+
+// NewTransport creates a new retrying transport over the base transport,
+// nil base uses the default transport.
+func NewTransport(base http.RoundTripper, policy Policy, logger *zap.Logger) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		base:   base,
+		policy: policy,
+		logger: logger,
+	}
+}
+
+// NewClient creates a new HTTP client retrying the requests by the policy.
+func NewClient(policy Policy, logger *zap.Logger) *http.Client {
+	return &http.Client{Transport: NewTransport(nil, policy, logger)}
+}
+
+// RoundTrip sends the request and retries it by the policy.
+// It returns the response of the last attempt.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+
+		if attempt >= t.policy.MaxAttempts || !t.retryable(ctx, resp, err) ||
+			(req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		delay := t.policy.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				if after > t.policy.MaxDelay {
+					return resp, err
+				}
+				delay = max(delay, after)
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+
+		t.logger.Warn("Retrying request",
+			zap.String("host", req.URL.Host),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Int("status", status(resp)),
+			zap.Error(err))
+
+		// the response of a retried attempt is dropped
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether the attempt failed with a transient error.
+func (t *Transport) retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled)
+	}
+	return Retryable(resp.StatusCode)
+}
+
+// backoff returns the delay before the retry after the attempt:
+// the exponential delay capped by the max delay, randomized in its upper half.
+func (p Policy) backoff(attempt int) time.Duration {
+	delay := p.InitialDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// retryAfter parses the Retry-After header given in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// status returns the status code of the response, 0 without a response.
+func status(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
@@ -0,0 +1,177 @@
+package retry
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusBadRequest, false},
+		{http.StatusNotFound, false},
+		{http.StatusRequestTimeout, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusNotImplemented, false},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusGatewayTimeout, true},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.status); got != tt.want {
+			t.Errorf("Retryable(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := Policy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 200 * time.Millisecond, 400 * time.Millisecond},
+		{5, 500 * time.Millisecond, time.Second},
+		{20, 500 * time.Millisecond, time.Second},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			if got := policy.backoff(tt.attempt); got < tt.min || got > tt.max {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", tt.attempt, got, tt.min, tt.max)
+			}
+		}
+	}
+
+	if got := (Policy{}).backoff(3); got != 0 {
+		t.Errorf("backoff without delays = %v, want 0", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int  // statuses of the attempts, the last one repeats
+		retryAfter string // Retry-After header of the failed attempts
+		body       string // body of a POST request, GET if empty
+		want       int    // status of the response
+		attempts   int32
+	}{
+		{
+			name:     "success is not retried",
+			statuses: []int{http.StatusOK},
+			want:     http.StatusOK,
+			attempts: 1,
+		},
+		{
+			name:     "transient failures are retried",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			want:     http.StatusOK,
+			attempts: 3,
+		},
+		{
+			name:     "attempts are limited",
+			statuses: []int{http.StatusInternalServerError},
+			want:     http.StatusInternalServerError,
+			attempts: 3,
+		},
+		{
+			name:     "client errors are not retried",
+			statuses: []int{http.StatusNotFound},
+			want:     http.StatusNotFound,
+			attempts: 1,
+		},
+		{
+			name:       "long Retry-After stops retries",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "3600",
+			want:       http.StatusTooManyRequests,
+			attempts:   1,
+		},
+		{
+			name:     "request body is sent again",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			body:     "payload",
+			want:     http.StatusOK,
+			attempts: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(attempts.Add(1))
+				if tt.body != "" {
+					if body, _ := io.ReadAll(r.Body); string(body) != tt.body {
+						t.Errorf("attempt %d body = %q, want %q", n, body, tt.body)
+					}
+				}
+				status := tt.statuses[min(n, len(tt.statuses))-1]
+				if status != http.StatusOK && tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			client := NewClient(Policy{
+				MaxAttempts:  3,
+				InitialDelay: time.Millisecond,
+				MaxDelay:     10 * time.Millisecond,
+			}, zap.NewNop())
+
+			var resp *http.Response
+			var err error
+			if tt.body != "" {
+				resp, err = client.Post(server.URL, "text/plain", strings.NewReader(tt.body))
+			} else {
+				resp, err = client.Get(server.URL)
+			}
+			if err != nil {
+				t.Fatalf("request error = %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if got := attempts.Load(); got != tt.attempts {
+				t.Errorf("attempts = %d, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+// Package review provides an interactive review of search plans in the terminal.
+package review
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dimdasci/seek/internal/models"
+	"go.uber.org/zap"
+)
+
+// ErrAborted is returned when the user quits the review.
+var ErrAborted = errors.New("plan review aborted")
+
+// Planner revises a search plan following the reviewer feedback.
+type Planner interface {
+	RevisePlan(ctx context.Context, query string, plan *models.Plan, feedback string) (*models.Plan, error)
+}
+
+// Reviewer lets the user approve and edit a search plan before execution.
+type Reviewer struct {
+	planner Planner
+	in      *bufio.Reader
+	out     io.Writer
+	logger  *zap.Logger
+}
+
+// NewReviewer creates a new plan reviewer reading commands from in and writing to out.
+func NewReviewer(planner Planner, in io.Reader, out io.Writer, logger *zap.Logger) *Reviewer {
+	return &Reviewer{
+		planner: planner,
+		in:      bufio.NewReader(in),
+		out:     out,
+		logger:  logger,
+	}
+}
+
+// Review shows the plan and applies the user commands until the plan is approved.
+// Every edit is checked by the plan rules, an invalid edit is rejected.
+// A plan refused by the planner can be approved by the user.
+// It returns the approved plan or ErrAborted if the user quits.
+func (r *Reviewer) Review(ctx context.Context, query string, plan *models.Plan) (*models.Plan, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("search plan is nil")
+	}
+
+	for {
+		r.print(plan)
+
+		line, err := r.ask("\n[a]pprove, [d]rop N, [e]dit N, [n]ew step, [r]eplan, [q]uit: ")
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var edited *models.Plan
+		approved := false
+		switch fields[0] {
+		case "a", "approve":
+			approved = true
+			edited = clonePlan(plan)
+			if !edited.Approved {
+				edited.Approved = true
+				edited.Reason = "Approved by the user"
+			}
+		case "d", "drop":
+			edited, err = r.drop(plan, fields)
+		case "e", "edit":
+			edited, err = r.edit(plan, fields)
+		case "n", "new":
+			edited, err = r.add(plan)
+		case "r", "replan":
+			edited, err = r.replan(ctx, query, plan)
+		case "q", "quit":
+			return nil, ErrAborted
+		default:
+			fmt.Fprintf(r.out, "Unknown command: %s\n", fields[0])
+			continue
+		}
+		if err != nil {
+			if errors.Is(err, ErrAborted) {
+				return nil, err
+			}
+			fmt.Fprintf(r.out, "%v\n", err)
+			continue
+		}
+
+		if err := edited.Validate(); err != nil {
+			fmt.Fprintf(r.out, "Invalid plan, the change is rejected: %v\n", err)
+			continue
+		}
+
+		if approved {
+			r.logger.Info("Search plan approved by the user")
+			return edited, nil
+		}
+		plan = edited
+	}
+}
+
+// drop removes the step at the position from the plan,
+// and from the dependencies of the other steps.
+func (r *Reviewer) drop(plan *models.Plan, fields []string) (*models.Plan, error) {
+	i, err := stepIndex(plan, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	edited := clonePlan(plan)
+	id := edited.SearchPlan[i].ID
+	edited.SearchPlan = append(edited.SearchPlan[:i], edited.SearchPlan[i+1:]...)
+	for j := range edited.SearchPlan {
+		step := &edited.SearchPlan[j]
+		deps := step.DependsOn[:0]
+		for _, dep := range step.DependsOn {
+			if dep != id {
+				deps = append(deps, dep)
+			}
+		}
+		step.DependsOn = deps
+	}
+
+	return edited, nil
+}
+
+// edit replaces the search query of the step at the position.
+// The query of a plan without steps is the query of a simple search.
+func (r *Reviewer) edit(plan *models.Plan, fields []string) (*models.Plan, error) {
+	edited := clonePlan(plan)
+
+	if len(edited.SearchPlan) == 0 {
+		query, err := r.ask("Search query: ")
+		if err != nil {
+			return nil, err
+		}
+		edited.SearchQuery = query
+		edited.SearchComplexity = "simple"
+		return edited, nil
+	}
+
+	i, err := stepIndex(plan, fields)
+	if err != nil {
+		return nil, err
+	}
+	query, err := r.ask("Search query (empty to analyse the previous steps): ")
+	if err != nil {
+		return nil, err
+	}
+	step := &edited.SearchPlan[i]
+	step.SearchQuery = query
+	if query == "" {
+		// depend on all previous steps
+		step.DependsOn = nil
+	}
+
+	return edited, nil
+}
+
+// add appends a new step to the plan.
+// A simple plan becomes complex with its query as the first step.
+func (r *Reviewer) add(plan *models.Plan) (*models.Plan, error) {
+	topic, err := r.ask("Topic: ")
+	if err != nil {
+		return nil, err
+	}
+	query, err := r.ask("Search query (empty to analyse the previous steps): ")
+	if err != nil {
+		return nil, err
+	}
+	request, err := r.ask("Sub-request (empty to use the topic): ")
+	if err != nil {
+		return nil, err
+	}
+	if request == "" {
+		request = topic
+	}
+
+	edited := clonePlan(plan)
+	if edited.SearchComplexity != "complex" {
+		if edited.SearchQuery != "" {
+			edited.SearchPlan = []models.Search{{
+				ID:          "1",
+				Topic:       edited.SearchQuery,
+				SearchQuery: edited.SearchQuery,
+				SubRequest:  edited.SearchQuery,
+			}}
+		}
+		edited.SearchComplexity = "complex"
+	}
+	edited.SearchPlan = append(edited.SearchPlan, models.Search{
+		ID:          nextID(edited),
+		Topic:       topic,
+		SearchQuery: query,
+		SubRequest:  request,
+	})
+
+	return edited, nil
+}
+
+// replan asks the planner for a new plan following the user feedback.
+func (r *Reviewer) replan(ctx context.Context, query string, plan *models.Plan) (*models.Plan, error) {
+	feedback, err := r.ask("Feedback: ")
+	if err != nil {
+		return nil, err
+	}
+	if feedback == "" {
+		return nil, fmt.Errorf("feedback is empty")
+	}
+
+	fmt.Fprintln(r.out, "Revising search plan...")
+	revised, err := r.planner.RevisePlan(ctx, query, plan, feedback)
+	if err != nil {
+		r.logger.Error("Failed to revise search plan", zap.Error(err))
+		return nil, fmt.Errorf("failed to revise search plan: %w", err)
+	}
+
+	return revised, nil
+}
+
+// print writes the plan topics and queries.
+func (r *Reviewer) print(plan *models.Plan) {
+	status := "approved"
+	if !plan.Approved {
+		status = "refused"
+	}
+	complexity := plan.SearchComplexity
+	if complexity == "" {
+		complexity = "no search"
+	}
+
+	fmt.Fprintf(r.out, "\nSearch plan: %s, %s\n", complexity, status)
+	if plan.Reason != "" {
+		fmt.Fprintf(r.out, "Reason: %s\n", plan.Reason)
+	}
+
+	if len(plan.SearchPlan) == 0 {
+		if plan.SearchQuery != "" {
+			fmt.Fprintf(r.out, "  query: %s\n", plan.SearchQuery)
+		}
+		return
+	}
+
+	positions := make(map[string]string, len(plan.SearchPlan))
+	for i, step := range plan.SearchPlan {
+		positions[step.ID] = strconv.Itoa(i + 1)
+	}
+	for i, step := range plan.SearchPlan {
+		fmt.Fprintf(r.out, "  %d. %s\n", i+1, step.Topic)
+		if step.SearchQuery != "" {
+			fmt.Fprintf(r.out, "     query: %s\n", step.SearchQuery)
+		}
+		if len(step.DependsOn) > 0 {
+			deps := make([]string, 0, len(step.DependsOn))
+			for _, dep := range step.DependsOn {
+				deps = append(deps, positions[dep])
+			}
+			fmt.Fprintf(r.out, "     after: %s\n", strings.Join(deps, ", "))
+		}
+	}
+}
+
+// ask writes the prompt and reads a line of the user input.
+// It returns ErrAborted when the input is closed.
+func (r *Reviewer) ask(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	line, err := r.in.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) && line != "" {
+			return strings.TrimSpace(line), nil
+		}
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(r.out)
+			return "", ErrAborted
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// stepIndex returns the index of the step at the position given in the command.
+func stepIndex(plan *models.Plan, fields []string) (int, error) {
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("step number is required")
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil || n < 1 || n > len(plan.SearchPlan) {
+		return 0, fmt.Errorf("no step %s in the plan", fields[1])
+	}
+	return n - 1, nil
+}
+
+// nextID returns the smallest numeric step ID not used in the plan.
+func nextID(plan *models.Plan) string {
+	used := make(map[string]bool, len(plan.SearchPlan))
+	for _, step := range plan.SearchPlan {
+		used[step.ID] = true
+	}
+	for n := 1; ; n++ {
+		if id := strconv.Itoa(n); !used[id] {
+			return id
+		}
+	}
+}
+
+// clonePlan returns a deep copy of the plan, so rejected edits keep the original.
+func clonePlan(plan *models.Plan) *models.Plan {
+	c := *plan
+	if plan.SearchPlan == nil {
+		return &c
+	}
+	c.SearchPlan = make([]models.Search, len(plan.SearchPlan))
+	for i, step := range plan.SearchPlan {
+		c.SearchPlan[i] = step
+		if step.DependsOn != nil {
+			c.SearchPlan[i].DependsOn = append([]string{}, step.DependsOn...)
+		}
+	}
+	return &c
+}
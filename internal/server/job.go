@@ -0,0 +1,122 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dimdasci/seek/internal/progress"
+	"github.com/dimdasci/seek/internal/service/search"
+)
+
+// JobStatus is the status of an answer job.
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"    // Job waits for a free slot
+	JobRunning   JobStatus = "running"   // Research is in progress
+	JobCompleted JobStatus = "completed" // Answer is complete
+	JobPartial   JobStatus = "partial"   // Answer is written without some of the research steps
+	JobFailed    JobStatus = "failed"    // Research failed, see the error
+)
+
+// done reports whether the job has finished.
+func (s JobStatus) done() bool {
+	return s == JobCompleted || s == JobPartial || s == JobFailed
+}
+
+// JobView is the state of an answer job returned by the API.
+type JobView struct {
+	ID         string           `json:"id"` // Job ID, the ID of the job session
+	Question   string           `json:"question"`
+	Status     JobStatus        `json:"status"`
+	Error      string           `json:"error,omitempty"`       // Failure message of a failed job
+	CreatedAt  time.Time        `json:"created_at"`            // Time the job was submitted
+	StartedAt  *time.Time       `json:"started_at,omitempty"`  // Time the research started
+	FinishedAt *time.Time       `json:"finished_at,omitempty"` // Time the job finished
+	Result     *search.Document `json:"result,omitempty"`      // Research document of a finished job
+}
+
+// job is an answer job running in the background.
+// It records the progress events of its research for the event streams.
+type job struct {
+	mu      sync.Mutex
+	view    JobView
+	events  []progress.Event
+	changed chan struct{} // Closed and replaced on every change of the job
+}
+
+// newJob creates a new queued job for the session.
+func newJob(id, question string) *job {
+	return &job{
+		view: JobView{
+			ID:        id,
+			Question:  question,
+			Status:    JobQueued,
+			CreatedAt: time.Now(),
+		},
+		changed: make(chan struct{}),
+	}
+}
+
+// Observe records the progress event of the job research.
+func (j *job) Observe(event progress.Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.events = append(j.events, event)
+	j.notify()
+}
+
+// start marks the job as running.
+func (j *job) start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	j.view.Status = JobRunning
+	j.view.StartedAt = &now
+	j.notify()
+}
+
+// finish records the outcome of the job.
+// The result is kept for a failed job too, if the research got that far.
+func (j *job) finish(status JobStatus, result *search.Document, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	j.view.Status = status
+	j.view.FinishedAt = &now
+	j.view.Result = result
+	if err != nil {
+		j.view.Error = err.Error()
+	}
+	j.notify()
+}
+
+// snapshot returns the job state, the events recorded after the first
+// `from` events and the channel closed on the next change of the job.
+func (j *job) snapshot(from int) (JobView, []progress.Event, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var events []progress.Event
+	if from < len(j.events) {
+		events = append(events, j.events[from:]...)
+	}
+	return j.view, events, j.changed
+}
+
+// finishedBefore reports whether the job finished before the time.
+func (j *job) finishedBefore(t time.Time) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.view.FinishedAt != nil && j.view.FinishedAt.Before(t)
+}
+
+// notify wakes up the event streams of the job, the caller holds the lock.
+func (j *job) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
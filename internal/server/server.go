@@ -0,0 +1,496 @@
+// Package server provides the HTTP API of seek: search plans, asynchronous
+// answer jobs with their progress streamed as Server-Sent Events, and web page reading.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/progress"
+	"github.com/dimdasci/seek/internal/service/search"
+	"github.com/dimdasci/seek/internal/service/webread"
+	"github.com/dimdasci/seek/internal/session"
+	"github.com/dimdasci/seek/internal/usage"
+	"go.uber.org/zap"
+)
+
+const (
+	maxBodySize       = 1 << 20          // Max size of a request body
+	keepAliveInterval = 15 * time.Second // Interval of the comments keeping an event stream open
+	maxPruneInterval  = time.Minute      // Max interval of the finished jobs cleanup
+)
+
+var (
+	// errBusy is returned when no request slot frees up within the request timeout.
+	errBusy = errors.New("server is busy, try again later")
+	// errQueueFull is returned when the answer job queue is full.
+	errQueueFull = errors.New("too many answer jobs, try again later")
+)
+
+// Planner builds the search plan for a question.
+type Planner interface {
+	PlanSearch(ctx context.Context, question string) (*models.Plan, error)
+}
+
+// SearchFactory creates the search service of an answer job. The service sends
+// the progress of the job to the observer and checks the report claims if verify is set.
+// It returns the service and the meter accounting the job usage, nil if not accounted.
+type SearchFactory func(observer progress.Observer, verify bool) (*search.Service, *usage.Meter, error)
+
+// Limits protect the upstream APIs from the load of the server.
+type Limits struct {
+	RequestTimeout time.Duration // Max duration of a plan or read request, 0 for no limit
+	JobTimeout     time.Duration // Max duration of an answer job, 0 for no limit
+	MaxRequests    int           // Max plan and read requests served at once, others wait
+	MaxReadURLs    int           // Max URLs of a read request, 0 for no limit
+	MaxJobs        int           // Max answer jobs running at once, others are queued
+	MaxQueued      int           // Max answer jobs waiting for a slot, new jobs are rejected when full
+	JobTTL         time.Duration // Time a finished job is kept in memory, 0 keeps the jobs until shutdown
+}
+
+// Server serves the HTTP API.
+type Server struct {
+	planner   Planner
+	reader    webread.WebReader
+	store     *session.Store
+	newSearch SearchFactory
+	logger    *zap.Logger
+	limits    Limits
+
+	requests chan struct{} // Slots of the plan and read requests
+	running  chan struct{} // Slots of the answer jobs
+
+	mu      sync.Mutex
+	jobs    map[string]*job // Answer jobs by ID
+	pending int             // Answer jobs queued or running
+
+	ctx    context.Context // Canceled on shutdown to stop the jobs
+	cancel context.CancelFunc
+	wg     sync.WaitGroup // Running jobs
+}
+
+// NewServer creates a new API server. Answer jobs are saved as sessions
+// in the store, so a job stopped by shutdown can be resumed by seek answer --resume.
+func NewServer(
+	planner Planner,
+	reader webread.WebReader,
+	store *session.Store,
+	newSearch SearchFactory,
+	logger *zap.Logger,
+	limits Limits) *Server {
+	if limits.MaxRequests < 1 {
+		limits.MaxRequests = 1
+	}
+	if limits.MaxJobs < 1 {
+		limits.MaxJobs = 1
+	}
+	if limits.MaxQueued < 0 {
+		limits.MaxQueued = 0
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		planner:   planner,
+		reader:    reader,
+		store:     store,
+		newSearch: newSearch,
+		logger:    logger,
+		limits:    limits,
+		requests:  make(chan struct{}, limits.MaxRequests),
+		running:   make(chan struct{}, limits.MaxJobs),
+		jobs:      make(map[string]*job),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	if limits.JobTTL > 0 {
+		go s.pruneJobs(min(limits.JobTTL, maxPruneInterval))
+	}
+	return s
+}
+
+// Handler returns the HTTP handler of the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/plan", s.handlePlan)
+	mux.HandleFunc("POST /v1/read", s.handleRead)
+	mux.HandleFunc("POST /v1/answer", s.handleAnswer)
+	mux.HandleFunc("GET /v1/jobs/{id}", s.handleJob)
+	mux.HandleFunc("GET /v1/jobs/{id}/events", s.handleEvents)
+	return mux
+}
+
+// Shutdown stops the running answer jobs and waits for them to save their progress.
+func (s *Server) Shutdown() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// planRequest is the body of a plan request.
+type planRequest struct {
+	Question string `json:"question"`
+}
+
+// handlePlan builds the search plan for the question.
+func (s *Server) handlePlan(w http.ResponseWriter, r *http.Request) {
+	var req planRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Question == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("question is required"))
+		return
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+	release, err := acquire(ctx, s.requests)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, errBusy)
+		return
+	}
+	defer release()
+
+	plan, err := s.planner.PlanSearch(ctx, req.Question)
+	if err != nil {
+		s.logger.Error("Failed to build search plan", zap.Error(err))
+		writeError(w, upstreamStatus(err), fmt.Errorf("failed to build search plan: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, plan)
+}
+
+// readRequest is the body of a read request.
+type readRequest struct {
+	URLs []string `json:"urls"`
+}
+
+// handleRead reads the web pages and returns them in markdown.
+func (s *Server) handleRead(w http.ResponseWriter, r *http.Request) {
+	var req readRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.URLs) == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("urls are required"))
+		return
+	}
+	if s.limits.MaxReadURLs > 0 && len(req.URLs) > s.limits.MaxReadURLs {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("at most %d urls can be read at once", s.limits.MaxReadURLs))
+		return
+	}
+
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
+	release, err := acquire(ctx, s.requests)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, errBusy)
+		return
+	}
+	defer release()
+
+	pages, err := s.reader.Read(ctx, req.URLs)
+	if err != nil {
+		s.logger.Error("Failed to read web pages", zap.Error(err))
+		writeError(w, upstreamStatus(err), fmt.Errorf("failed to read web pages: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pages)
+}
+
+// answerRequest is the body of an answer request.
+type answerRequest struct {
+	Question string       `json:"question"`         // Question, defaults to the search query of the plan
+	Plan     *models.Plan `json:"plan,omitempty"`   // Plan to run instead of building a new one
+	Verify   bool         `json:"verify,omitempty"` // Check the report claims against the sources
+}
+
+// handleAnswer starts an answer job and returns it without waiting for the result.
+func (s *Server) handleAnswer(w http.ResponseWriter, r *http.Request) {
+	var req answerRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Plan != nil {
+		if err := req.Plan.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid plan: %w", err))
+			return
+		}
+		if req.Question == "" {
+			req.Question = req.Plan.SearchQuery
+		}
+	}
+	if req.Question == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("question or plan is required"))
+		return
+	}
+
+	// reserve a place in the queue before the session is created
+	s.mu.Lock()
+	if s.pending >= s.limits.MaxJobs+s.limits.MaxQueued {
+		s.mu.Unlock()
+		w.Header().Set("Retry-After", "60")
+		writeError(w, http.StatusTooManyRequests, errQueueFull)
+		return
+	}
+	s.pending++
+	s.mu.Unlock()
+
+	sess, err := s.createSession(req)
+	if err != nil {
+		s.mu.Lock()
+		s.pending--
+		s.mu.Unlock()
+		s.logger.Error("Failed to create session", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	j := newJob(sess.ID, sess.Question)
+	s.mu.Lock()
+	s.jobs[sess.ID] = j
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	s.logger.Info("Answer job queued",
+		zap.String("job", sess.ID),
+		zap.String("question", sess.Question))
+	go s.run(j, sess, req.Verify)
+
+	view, _, _ := j.snapshot(0)
+	w.Header().Set("Location", "/v1/jobs/"+sess.ID)
+	writeJSON(w, http.StatusAccepted, view)
+}
+
+// createSession creates the session of the answer request with its plan, if any.
+func (s *Server) createSession(req answerRequest) (*session.Session, error) {
+	sess, err := s.store.Create(req.Question)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	if req.Plan != nil {
+		if err := sess.SetPlan(req.Plan); err != nil {
+			return nil, fmt.Errorf("failed to save search plan: %w", err)
+		}
+	}
+	return sess, nil
+}
+
+// run runs the research of the job once a job slot is free.
+// A panic of the run fails the job instead of the server.
+func (s *Server) run(j *job, sess *session.Session, verify bool) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		s.pending--
+		s.mu.Unlock()
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Answer job panicked",
+				zap.String("job", sess.ID),
+				zap.Any("panic", r),
+				zap.Stack("stack"))
+			j.finish(JobFailed, nil, fmt.Errorf("internal error: %v", r))
+		}
+	}()
+
+	release, err := acquire(s.ctx, s.running)
+	if err != nil {
+		j.finish(JobFailed, nil, fmt.Errorf("server is shutting down"))
+		return
+	}
+	defer release()
+
+	ctx := s.ctx
+	if s.limits.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.limits.JobTimeout)
+		defer cancel()
+	}
+
+	j.start()
+	s.logger.Info("Answer job started", zap.String("job", sess.ID))
+
+	searchService, meter, err := s.newSearch(j, verify)
+	if err != nil {
+		s.logger.Error("Failed to create search service", zap.Error(err))
+		j.finish(JobFailed, nil, fmt.Errorf("failed to create search service: %w", err))
+		return
+	}
+
+	result, err := searchService.Search(ctx, sess, nil)
+	if err != nil {
+		s.logger.Error("Answer job failed", zap.String("job", sess.ID), zap.Error(err))
+		j.finish(JobFailed, nil, err)
+		return
+	}
+
+	doc := searchService.Document(sess, result)
+	doc.Usage = meter.Report()
+	status := JobCompleted
+	if result.Partial() {
+		status = JobPartial
+	}
+	s.logger.Info("Answer job finished",
+		zap.String("job", sess.ID),
+		zap.String("status", string(status)))
+	j.finish(status, doc, nil)
+}
+
+// handleJob returns the state of the job with its result when it has finished.
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.job(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job not found"))
+		return
+	}
+
+	view, _, _ := j.snapshot(0)
+	writeJSON(w, http.StatusOK, view)
+}
+
+// handleEvents streams the progress events of the job as Server-Sent Events,
+// starting after the Last-Event-ID if given. The stream ends with a done event
+// holding the job state once the job has finished.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.job(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job not found"))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	next, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+	next = max(next, 0)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		view, events, changed := j.snapshot(next)
+		for _, event := range events {
+			next++
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", next, event.Type, data)
+		}
+		if view.Status.done() {
+			view.Result = nil
+			data, _ := json.Marshal(view)
+			fmt.Fprintf(w, "event: done\ndata: %s\n\n", data)
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// job returns the job with the ID.
+func (s *Server) job(id string) (*job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	return j, ok
+}
+
+// pruneJobs drops the jobs finished longer than the job TTL ago
+// every interval until the server shuts down.
+func (s *Server) pruneJobs(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.ctx.Done():
+			return
+		}
+
+		expired := time.Now().Add(-s.limits.JobTTL)
+		s.mu.Lock()
+		for id, j := range s.jobs {
+			if j.finishedBefore(expired) {
+				delete(s.jobs, id)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// requestContext returns the context of the request limited by the request timeout.
+func (s *Server) requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	if s.limits.RequestTimeout > 0 {
+		return context.WithTimeout(r.Context(), s.limits.RequestTimeout)
+	}
+	return context.WithCancel(r.Context())
+}
+
+// acquire takes a slot, waiting until one is free or the context is done.
+// It returns the function releasing the slot.
+func acquire(ctx context.Context, slots chan struct{}) (func(), error) {
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// upstreamStatus returns the status code of a request failed by an upstream service.
+func upstreamStatus(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// readJSON decodes the JSON body of the request into v.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// writeJSON writes v as the JSON response with the status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the error as the JSON response with the status code.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,68 @@
+package relevance
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/dimdasci/seek/internal/llm"
+)
+
+// localDimensions is the size of the local embedding vectors.
+const localDimensions = 1024
+
+// stopWords are frequent English words ignored by the local embedder.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"in": true, "is": true, "it": true, "its": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "this": true, "to": true, "was": true,
+	"were": true, "what": true, "which": true, "with": true,
+}
+
+// LocalEmbedder embeds texts locally as hashed bags of words.
+// It needs no model or network and catches pages sharing no words with the request,
+// the similarities are lower than the ones of embedding models.
+type LocalEmbedder struct{}
+
+// NewLocalEmbedder creates a new local embedder.
+func NewLocalEmbedder() *LocalEmbedder {
+	return &LocalEmbedder{}
+}
+
+// Embed returns the embedding vectors of the texts in the texts order.
+// Local embeddings use no model and no tokens.
+func (e *LocalEmbedder) Embed(ctx context.Context, texts []string) (*llm.Embeddings, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vectors[i] = embedWords(text)
+	}
+	return &llm.Embeddings{Vectors: vectors}, nil
+}
+
+// embedWords returns the vector of the sublinear word frequencies
+// hashed into the local dimensions.
+func embedWords(text string) []float64 {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if !stopWords[word] {
+			counts[word]++
+		}
+	}
+
+	vector := make([]float64, localDimensions)
+	for word, count := range counts {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		vector[h.Sum32()%localDimensions] += 1 + math.Log(float64(count))
+	}
+	return vector
+}
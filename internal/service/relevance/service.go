@@ -0,0 +1,151 @@
+// Package relevance provides a service to filter out pages irrelevant to a request
+// by the similarity of their embeddings before the model analyses them.
+package relevance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dimdasci/seek/internal/llm"
+	"github.com/dimdasci/seek/internal/models"
+	"go.uber.org/zap"
+)
+
+// Embedder converts texts into embedding vectors.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) (*llm.Embeddings, error)
+}
+
+// Service drops the pages not similar to the request and keeps
+// the chunks of the remaining pages most similar to the request.
+type Service struct {
+	embedder    Embedder
+	logger      *zap.Logger
+	threshold   float64 // Min similarity of the best page chunk to keep the page
+	topK        int     // Max chunks kept per page, 0 keeps all chunks
+	chunkTokens int64   // Max tokens of a page chunk
+}
+
+// NewService creates a new relevance filter service.
+func NewService(
+	embedder Embedder,
+	logger *zap.Logger,
+	threshold float64,
+	topK int,
+	chunkTokens int64) *Service {
+	if chunkTokens < 1 {
+		chunkTokens = 512
+	}
+	return &Service{
+		embedder:    embedder,
+		logger:      logger,
+		threshold:   threshold,
+		topK:        topK,
+		chunkTokens: chunkTokens,
+	}
+}
+
+// chunk is a part of the page content with its similarity to the request.
+type chunk struct {
+	page       int     // Index of the page
+	position   int     // Position of the chunk in the page
+	text       string  // Chunk content
+	similarity float64 // Cosine similarity to the request
+}
+
+// Filter returns the pages similar to the request in the pages order.
+// The content of the returned pages is reduced to the top chunks in the page order.
+func (s *Service) Filter(ctx context.Context, request string, pages []models.Page) ([]models.Page, error) {
+	if len(pages) == 0 {
+		return nil, nil
+	}
+
+	var chunks []*chunk
+	for i, page := range pages {
+		for j, text := range llm.SplitMarkdown(page.Content, s.chunkTokens) {
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			chunks = append(chunks, &chunk{page: i, position: j, text: text})
+		}
+	}
+
+	texts := make([]string, 0, len(chunks)+1)
+	texts = append(texts, request)
+	for _, c := range chunks {
+		texts = append(texts, c.text)
+	}
+	embeddings, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed pages: %w", err)
+	}
+	vectors := embeddings.Vectors
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d texts", len(vectors), len(texts))
+	}
+
+	// group the chunks by page
+	byPage := make([][]*chunk, len(pages))
+	for i, c := range chunks {
+		c.similarity = cosine(vectors[0], vectors[i+1])
+		byPage[c.page] = append(byPage[c.page], c)
+	}
+
+	kept := make([]models.Page, 0, len(pages))
+	for i, page := range pages {
+		pageChunks := byPage[i]
+		sort.SliceStable(pageChunks, func(a, b int) bool {
+			return pageChunks[a].similarity > pageChunks[b].similarity
+		})
+
+		if len(pageChunks) == 0 || pageChunks[0].similarity < s.threshold {
+			s.logger.Debug("Page is not similar to the request",
+				zap.String("url", page.URL),
+				zap.Int("chunks", len(pageChunks)))
+			continue
+		}
+		best := pageChunks[0].similarity
+
+		if s.topK > 0 && len(pageChunks) > s.topK {
+			pageChunks = pageChunks[:s.topK]
+			sort.Slice(pageChunks, func(a, b int) bool {
+				return pageChunks[a].position < pageChunks[b].position
+			})
+			texts := make([]string, len(pageChunks))
+			for j, c := range pageChunks {
+				texts[j] = strings.TrimSpace(c.text)
+			}
+			page.Content = strings.Join(texts, "\n\n")
+		}
+
+		s.logger.Debug("Page is similar to the request",
+			zap.String("url", page.URL),
+			zap.Float64("similarity", best),
+			zap.Int("chunks", len(pageChunks)))
+		kept = append(kept, page)
+	}
+
+	s.logger.Info("Filtered pages by similarity",
+		zap.String("request", request),
+		zap.Int("pages", len(pages)),
+		zap.Int("kept", len(kept)))
+
+	return kept, nil
+}
+
+// cosine returns the cosine similarity of the vectors, 0 for zero vectors.
+func cosine(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range min(len(a), len(b)) {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
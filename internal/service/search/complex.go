@@ -2,59 +2,258 @@ package search
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
+	"github.com/dimdasci/seek/internal/llm"
 	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/progress"
+	"github.com/dimdasci/seek/internal/session"
+	"github.com/dimdasci/seek/internal/usage"
 	"go.uber.org/zap"
 )
 
 // executeComplexSearch performs a complex search for the given steps.
-// It returns a string with the search results.
-func (s *Service) executeComplexSearch(ctx context.Context, plan *models.Plan) string {
-	var topics string = ""
+// The plan is executed as a dependency graph: every step starts once the steps
+// it depends on are completed, limited by the service concurrency.
+// Steps without a search query analyse the findings of their dependencies.
+// Steps completed in the session are not executed again.
+// Steps are skipped when the run budget is running out.
+// Failed and skipped steps do not stop the run, the report is written from
+// the completed steps, a truncated report is kept as a partial outcome.
+// It returns a string with the search results and the outcomes of the steps in the plan order.
+func (s *Service) executeComplexSearch(ctx context.Context, r *research, plan *models.Plan) (string, []models.StepOutcome, error) {
 	var outline string = ""
 
-	for i, step := range plan.SearchPlan {
-		policy := fmt.Sprintf("%s\n\n%s", step.SubRequest, step.FinalAnswerOutline)
+	// results keeps the findings and outcomes of every step in the plan order
+	results := make([]string, len(plan.SearchPlan))
+	outcomes := make([]models.StepOutcome, len(plan.SearchPlan))
 
+	// done channels are closed when the step with the id is completed
+	index := make(map[string]int, len(plan.SearchPlan))
+	done := make([]chan struct{}, len(plan.SearchPlan))
+	for i, step := range plan.SearchPlan {
+		index[step.ID] = i
+		done[i] = make(chan struct{})
 		outline += fmt.Sprintf("- %d. %s\n", i+1, step.Topic)
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.concurrency)
+
+	for i, step := range plan.SearchPlan {
+		wg.Add(1)
+		go func(i int, step models.Search) {
+			defer wg.Done()
+			defer close(done[i])
 
-		fmt.Printf(
-			"Step %d. %s\n", i+1, step.Topic)
-
-		s.logger.Debug("Complex search step",
-			zap.Int("step", i+1),
-			zap.String("topic", step.Topic),
-			zap.String("sub_request", step.SubRequest),
-			zap.String("search_query", step.SearchQuery),
-			zap.String("final_answer_outline", step.FinalAnswerOutline))
-
-		switch step.SearchQuery {
-		case "":
-			if topics == "" {
-				s.logger.Debug("Topics are empty for an empty search query",
-					zap.Int("step", i+1),
-					zap.String("topic", step.Topic))
-				continue
+			// wait for dependencies
+			for _, dep := range step.DependsOn {
+				select {
+				case <-done[index[dep]]:
+				case <-ctx.Done():
+					return
+				}
 			}
-			topics += "\n\n" + s.openaiClient.CompileFindings(topics, step.Topic, policy)
-		default:
-			topics += "\n\n" + s.executeSimpleSearch(ctx,
-				step.Topic,
-				step.SearchQuery,
-				policy)
-		}
 
-		s.logger.Debug("Complex search step result",
-			zap.Int("step", i+1),
-			zap.String("topic", step.Topic))
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			restored := r.session.Step(step.ID).Done
+			result, err := s.executeStep(ctx, r, i, step, results, index)
+			if err != nil && !errors.Is(err, ErrOverBudget) {
+				s.logger.Error("Service: search step failed",
+					zap.String("step", step.ID),
+					zap.String("topic", step.Topic),
+					zap.Error(err))
+			}
+			results[i] = result
+			outcomes[i] = newOutcome(step.ID, step.Topic, err)
+			if !restored && outcomes[i].Status != models.StepSkipped {
+				s.emitStepDone("Step", i, outcomes[i])
+			}
+		}(i, step)
+	}
+	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
 	}
-	fmt.Print("Working on the final answer...\n\n")
-	return s.openaiClient.WriteReport(
+
+	topics := joinTopics(results)
+	if topics == "" {
+		// the budget ran out before any findings, the run ends with what it has
+		for _, outcome := range outcomes {
+			if outcome.Reason == models.ReasonBudget {
+				return s.budgetReport(plan.SearchQuery), outcomes, nil
+			}
+		}
+		for _, outcome := range outcomes {
+			if !outcome.Completed() {
+				return "", outcomes, fmt.Errorf("%w: step %q %s: %s",
+					ErrNoFindings, outcome.Topic, outcome.Status, outcome.Error)
+			}
+		}
+		return "", outcomes, fmt.Errorf("%w: no step has findings", ErrNoFindings)
+	}
+
+	s.emit(progress.Event{Type: progress.ReportStarted, Message: "Working on the final answer..."})
+	report, err := s.llm.WriteReport(
 		ctx,
 		&topics,
 		&plan.SearchQuery,
 		&outline,
-		&plan.CompilationPolicy)
+		&plan.CompilationPolicy,
+		r.stream)
+	switch {
+	case errors.Is(err, llm.ErrTruncated) && report != "":
+		outcome := newOutcome(reportStepID, "Final report", err)
+		outcome.Status = models.StepPartial
+		outcomes = append(outcomes, outcome)
+	case err != nil:
+		return "", outcomes, err
+	}
+	s.emit(progress.Event{Type: progress.ReportDone, Message: "Final answer is written"})
+
+	return report, outcomes, nil
+}
+
+// emitStepDone sends the step done event with the outcome of the step i,
+// the label names the step in the message.
+func (s *Service) emitStepDone(label string, i int, outcome models.StepOutcome) {
+	message := fmt.Sprintf("%s %d. %s (%s)", label, i+1, outcome.Topic, outcome.Status)
+	if !outcome.Completed() {
+		message = fmt.Sprintf("%s %d. %s (%s: %s)", label, i+1, outcome.Topic, outcome.Status, outcome.Error)
+	}
+	s.emit(progress.Event{
+		Type:    progress.StepDone,
+		Step:    i + 1,
+		StepID:  outcome.ID,
+		Topic:   outcome.Topic,
+		Status:  outcome.Status,
+		Error:   outcome.Error,
+		Message: message,
+	})
+}
+
+// executeStep executes a single step of the complex search plan.
+// Dependencies of the step must be completed before the call.
+// It returns a string with the step findings, or an error if the step fails or is skipped.
+func (s *Service) executeStep(
+	ctx context.Context,
+	r *research,
+	i int,
+	step models.Search,
+	results []string,
+	index map[string]int,
+) (string, error) {
+	if saved := r.session.Step(step.ID); saved.Done {
+		s.emit(progress.Event{
+			Type:    progress.StepRestored,
+			Step:    i + 1,
+			StepID:  step.ID,
+			Topic:   step.Topic,
+			Message: fmt.Sprintf("Step %d. %s (restored)", i+1, step.Topic),
+		})
+		return saved.Topic, nil
+	}
+
+	// skip the remaining searches, then the remaining analyses to stay within the budget,
+	// the final report is compiled from the completed steps
+	if level := s.meter.Level(); level == usage.LevelExhausted ||
+		(level == usage.LevelMinimal && step.SearchQuery != "") {
+		s.emit(progress.Event{
+			Type:    progress.StepSkipped,
+			Step:    i + 1,
+			StepID:  step.ID,
+			Topic:   step.Topic,
+			Message: fmt.Sprintf("Step %d. %s (skipped, budget is running out)", i+1, step.Topic),
+		})
+		s.meter.Note(fmt.Sprintf("skipped step %d. %s", i+1, step.Topic))
+		return "", ErrOverBudget
+	}
+
+	policy := fmt.Sprintf("%s\n\n%s", step.SubRequest, step.FinalAnswerOutline)
+
+	s.logger.Debug("Complex search step",
+		zap.Int("step", i+1),
+		zap.String("id", step.ID),
+		zap.String("topic", step.Topic),
+		zap.String("sub_request", step.SubRequest),
+		zap.String("search_query", step.SearchQuery),
+		zap.Strings("depends_on", step.DependsOn),
+		zap.String("final_answer_outline", step.FinalAnswerOutline))
+
+	var result string
+	var err error
+	switch step.SearchQuery {
+	case "":
+		// collect findings of the declared dependencies only
+		findings := make([]string, 0, len(step.DependsOn))
+		for _, dep := range step.DependsOn {
+			findings = append(findings, results[index[dep]])
+		}
+
+		topics := joinTopics(findings)
+		if topics == "" {
+			s.logger.Debug("Topics are empty for an empty search query",
+				zap.Int("step", i+1),
+				zap.String("topic", step.Topic))
+			return "", fmt.Errorf("%w: dependencies have no findings", ErrNoFindings)
+		}
+
+		s.emitStepStarted(i, step)
+
+		result, err = s.llm.CompileFindings(ctx, topics, step.Topic, policy)
+		if err != nil {
+			return "", err
+		}
+		s.saveStep(r, step.ID, func(saved *session.Step) {
+			saved.Done = true
+			saved.Topic = result
+		})
+	default:
+		s.emitStepStarted(i, step)
+
+		result, err = s.executeSimpleSearch(ctx,
+			r,
+			step.ID,
+			step.Topic,
+			step.SearchQuery,
+			policy)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	s.logger.Debug("Complex search step result",
+		zap.Int("step", i+1),
+		zap.String("topic", step.Topic))
+
+	return result, nil
+}
+
+// emitStepStarted sends the step started event of the step i.
+func (s *Service) emitStepStarted(i int, step models.Search) {
+	s.emit(progress.Event{
+		Type:    progress.StepStarted,
+		Step:    i + 1,
+		StepID:  step.ID,
+		Topic:   step.Topic,
+		Query:   step.SearchQuery,
+		Message: fmt.Sprintf("Step %d. %s", i+1, step.Topic),
+	})
+}
+
+// joinTopics joins the findings of completed steps keeping the given order.
+func joinTopics(results []string) string {
+	var topics string = ""
+	for _, result := range results {
+		if result == "" {
+			continue
+		}
+		topics += "\n\n" + result
+	}
+	return topics
 }
@@ -0,0 +1,157 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/session"
+	"github.com/dimdasci/seek/internal/usage"
+)
+
+// Relevance verdicts of a source
+const (
+	VerdictRelevant    = "relevant"     // Model found the page relevant in some step
+	VerdictIrrelevant  = "irrelevant"   // Model or the prefilter found the page irrelevant
+	VerdictNotAnalysed = "not_analysed" // Page analysis failed or did not run
+)
+
+// Document is the machine-readable result of a research run.
+type Document struct {
+	SessionID string           `json:"session_id"`
+	Question  string           `json:"question"`
+	Plan      *models.Plan     `json:"plan"`
+	Steps     []DocumentStep   `json:"steps"`
+	Sources   []DocumentSource `json:"sources"`
+	Report    string           `json:"report"`          // Final report in markdown
+	Partial   bool             `json:"partial"`         // Some steps are not completed
+	Usage     *usage.Report    `json:"usage,omitempty"` // Usage of the run, nil if not accounted
+}
+
+// DocumentStep represents a step of the research plan.
+type DocumentStep struct {
+	ID      string               `json:"id"`
+	Topic   string               `json:"topic"`
+	Queries []string             `json:"queries,omitempty"` // Search query followed by the refined queries
+	Status  models.StepStatus    `json:"status"`
+	Reason  models.FailureReason `json:"reason,omitempty"`
+	Error   string               `json:"error,omitempty"`
+	Sources []string             `json:"sources,omitempty"` // IDs of the sources read in the step
+}
+
+// DocumentSource represents a page read during the research.
+type DocumentSource struct {
+	ID        string `json:"id"`
+	URL       string `json:"url"`
+	Title     string `json:"title,omitempty"`
+	Citation  int    `json:"citation,omitempty"`   // Number the report cites the source with, 0 if not cited
+	Verdict   string `json:"verdict"`              // Relevance verdict
+	KeyPoints string `json:"key_points,omitempty"` // Key points extracted from the page
+}
+
+// Document returns the machine-readable document of the research run
+// from the session and the result of its search.
+func (s *Service) Document(sess *session.Session, result *Result) *Document {
+	doc := &Document{
+		SessionID: sess.ID,
+		Question:  sess.Question,
+		Plan:      sess.Plan,
+		Steps:     []DocumentStep{},
+		Sources:   []DocumentSource{},
+		Report:    result.Report,
+		Partial:   result.Partial(),
+	}
+
+	outcomes := make(map[string]models.StepOutcome, len(result.Steps))
+	for _, outcome := range result.Steps {
+		outcomes[outcome.ID] = outcome
+	}
+
+	// steps of the plan, a simple plan has a single step
+	var steps []models.Search
+	if sess.Plan != nil && sess.Plan.SearchComplexity == "complex" {
+		steps = sess.Plan.SearchPlan
+	} else if sess.Plan != nil {
+		steps = []models.Search{{
+			ID:          simpleStepID,
+			Topic:       sess.Plan.SearchQuery,
+			SearchQuery: sess.Plan.SearchQuery,
+		}}
+	}
+
+	analyses := make(map[string][]models.PageAnalysis)
+	read := make(map[string]bool)
+	for _, step := range steps {
+		outcome, ok := outcomes[step.ID]
+		if !ok {
+			outcome = models.StepOutcome{Status: models.StepCompleted}
+		}
+		ds := DocumentStep{
+			ID:     step.ID,
+			Topic:  step.Topic,
+			Status: outcome.Status,
+			Reason: outcome.Reason,
+			Error:  outcome.Error,
+		}
+		if step.SearchQuery != "" {
+			ds.Queries = append(ds.Queries, step.SearchQuery)
+		}
+
+		for i, saved := range withDeepening(sess, step.ID) {
+			if i == 0 {
+				ds.Queries = append(ds.Queries, saved.Queries...)
+			}
+			for _, page := range saved.Pages {
+				read[page.ID] = true
+				ds.Sources = appendUnique(ds.Sources, page.ID)
+				if analysis, ok := saved.Analyses[page.URL]; ok {
+					analyses[page.ID] = append(analyses[page.ID], analysis)
+				}
+			}
+		}
+		doc.Steps = append(doc.Steps, ds)
+	}
+
+	// citation numbers of the report
+	sources := newSourceRegistry(sess.Sources)
+	_, cited := s.renderCitations(sess.Draft, sources)
+	citations := make(map[string]int, len(cited))
+	for i, source := range cited {
+		citations[source.ID] = i + 1
+	}
+
+	for _, source := range sources.list() {
+		if !read[source.ID] {
+			continue
+		}
+		ds := DocumentSource{
+			ID:       source.ID,
+			URL:      source.URL,
+			Title:    source.Title,
+			Citation: citations[source.ID],
+			Verdict:  VerdictNotAnalysed,
+		}
+		var keyPoints []string
+		for _, analysis := range analyses[source.ID] {
+			if analysis.Relevant {
+				ds.Verdict = VerdictRelevant
+				keyPoints = appendUnique(keyPoints, strings.TrimSpace(analysis.KeyPoints))
+			} else if ds.Verdict == VerdictNotAnalysed {
+				ds.Verdict = VerdictIrrelevant
+			}
+		}
+		ds.KeyPoints = strings.Join(keyPoints, "\n\n")
+		doc.Sources = append(doc.Sources, ds)
+	}
+
+	return doc
+}
+
+// appendUnique appends the value to the list if it is not there yet.
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,216 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/progress"
+	"github.com/dimdasci/seek/internal/session"
+	"github.com/dimdasci/seek/internal/usage"
+	"go.uber.org/zap"
+)
+
+// FollowUp answers the follow-up question of a completed session.
+// The question is answered from the findings and the page key points stored in
+// the session, the web is searched only if the planner finds them insufficient.
+// The answer is appended to the session report and shares its bibliography.
+// Failed and skipped searches do not stop the run, the answer is compiled without them.
+// It returns the updated report with the outcomes of the follow-up searches.
+func (s *Service) FollowUp(ctx context.Context, sess *session.Session, question string) (*Result, error) {
+	s.logger.Info("Service: answering follow-up",
+		zap.String("session", sess.ID),
+		zap.String("question", question))
+
+	if sess.Draft == "" {
+		return nil, fmt.Errorf("session %s has no report yet, resume it first", sess.ID)
+	}
+
+	followUp, i, err := sess.FollowUp(question)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &research{
+		session: sess,
+		sources: newSourceRegistry(sess.Sources),
+	}
+	material := sessionMaterial(sess, i)
+
+	plan := followUp.Plan
+	if plan == nil {
+		s.emit(progress.Event{Type: progress.Planning, Message: "Checking the previous findings..."})
+		plan, err = s.llm.PlanFollowUp(ctx, sess.Question, material, question)
+		if err != nil {
+			s.logger.Error("Service: failed to plan follow-up",
+				zap.Error(err))
+			return nil, fmt.Errorf("failed to plan follow-up: %w", err)
+		}
+		if err := sess.UpdateFollowUp(i, func(f *session.FollowUp) { f.Plan = plan }); err != nil {
+			return nil, err
+		}
+	}
+
+	var outcomes []models.StepOutcome
+	if plan.Covered {
+		s.emit(progress.Event{Type: progress.PlanBuilt, Message: "Answering from the previous findings..."})
+	} else {
+		s.emit(progress.Event{
+			Type:    progress.PlanBuilt,
+			Steps:   len(plan.SearchQueries),
+			Message: fmt.Sprintf("Searching for the missing information: %s", plan.Reason),
+		})
+		for j, query := range plan.SearchQueries {
+			stepID := followUpStepID(i, j)
+			saved := sess.Step(stepID)
+			findings := saved.Topic
+			var err error
+			if !saved.Done && s.meter.Level() >= usage.LevelMinimal {
+				s.emit(progress.Event{
+					Type:    progress.StepSkipped,
+					Step:    j + 1,
+					StepID:  stepID,
+					Topic:   query,
+					Query:   query,
+					Message: fmt.Sprintf("Search %d. %s (skipped, budget is running out)", j+1, query),
+				})
+				s.meter.Note(fmt.Sprintf("skipped search %s", query))
+				outcomes = append(outcomes, newOutcome(stepID, query, ErrOverBudget))
+				continue
+			}
+			if !saved.Done {
+				s.emit(progress.Event{
+					Type:    progress.StepStarted,
+					Step:    j + 1,
+					StepID:  stepID,
+					Topic:   query,
+					Query:   query,
+					Message: fmt.Sprintf("Search %d. %s", j+1, query),
+				})
+				findings, err = s.executeSimpleSearch(ctx, r, stepID, query, query, plan.CompilationPolicy)
+				if err != nil {
+					s.logger.Error("Service: follow-up search failed",
+						zap.String("query", query),
+						zap.Error(err))
+				}
+			}
+			outcome := newOutcome(stepID, query, err)
+			outcomes = append(outcomes, outcome)
+			if !saved.Done {
+				s.emitStepDone("Search", j, outcome)
+			}
+			if err == nil {
+				material += "\n\n" + findings
+			}
+		}
+
+		// keep the partial progress in the session instead of an answer of an interrupted run
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	answer, err := s.llm.CompileFindings(ctx, material, question, plan.CompilationPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile the follow-up answer: %w", err)
+	}
+	if err := sess.UpdateFollowUp(i, func(f *session.FollowUp) {
+		f.Draft = answer
+		f.Outcomes = outcomes
+	}); err != nil {
+		return nil, err
+	}
+
+	// the claims check of the session report is kept, the answers are not checked
+	report, _ := s.renderCitations(sessionDraft(sess), r.sources)
+	if sess.Verification != nil {
+		report = strings.TrimRight(report, "\n") + "\n\n" + sess.Verification.Markdown()
+	}
+	report = appendIncomplete(report, sessionOutcomes(sess))
+	if err := sess.SetReport(report); err != nil {
+		return nil, err
+	}
+
+	return &Result{Report: report, Steps: outcomes}, nil
+}
+
+// sessionOutcomes returns the step outcomes of the session run
+// followed by the outcomes of the follow-up searches.
+func sessionOutcomes(sess *session.Session) []models.StepOutcome {
+	outcomes := append([]models.StepOutcome(nil), sess.Outcomes...)
+	for _, followUp := range sess.FollowUps {
+		outcomes = append(outcomes, followUp.Outcomes...)
+	}
+	return outcomes
+}
+
+// sessionMaterial returns the findings of the session steps in the plan order
+// and the answers to the first n follow-ups, followed by the key points of the relevant pages.
+// The findings come first, as the model calls cut the material that does not fit the prompt from the end.
+func sessionMaterial(sess *session.Session, n int) string {
+	var ids []string
+	if sess.Plan != nil && sess.Plan.SearchComplexity == "complex" {
+		for _, step := range sess.Plan.SearchPlan {
+			ids = append(ids, step.ID)
+		}
+	} else {
+		ids = append(ids, simpleStepID)
+	}
+	for i, followUp := range sess.FollowUps[:n] {
+		if followUp.Plan == nil {
+			continue
+		}
+		for j := range followUp.Plan.SearchQueries {
+			ids = append(ids, followUpStepID(i, j))
+		}
+	}
+
+	var findings, keyPoints []string
+	for _, id := range ids {
+		findings = append(findings, sess.Step(id).Topic)
+		for _, step := range withDeepening(sess, id) {
+			for _, page := range step.Pages {
+				if analysis, ok := step.Analyses[page.URL]; ok && analysis.Relevant {
+					keyPoints = append(keyPoints, analysis.KeyPoints)
+				}
+			}
+		}
+	}
+	for _, followUp := range sess.FollowUps[:n] {
+		findings = append(findings, followUp.Draft)
+	}
+
+	return strings.TrimSpace(joinTopics(findings) + joinTopics(keyPoints))
+}
+
+// withDeepening returns the session step with the ID followed by its deepening steps,
+// the pages of the refined searches are kept in the deepening steps.
+func withDeepening(sess *session.Session, id string) []session.Step {
+	step := sess.Step(id)
+	steps := []session.Step{step}
+	for round, gap := range step.Gaps {
+		for k := range gap.Queries {
+			steps = append(steps, sess.Step(deepeningStepID(id, round, k)))
+		}
+	}
+	return steps
+}
+
+// sessionDraft returns the session report citing source IDs
+// with the answers to the follow-ups appended, each under a heading with its question.
+func sessionDraft(sess *session.Session) string {
+	drafts := []string{strings.TrimRight(sess.Draft, "\n")}
+	for _, followUp := range sess.FollowUps {
+		if followUp.Draft != "" {
+			drafts = append(drafts, fmt.Sprintf("## Follow-up: %s\n\n%s",
+				strings.TrimSpace(followUp.Question), strings.TrimSpace(followUp.Draft)))
+		}
+	}
+	return strings.Join(drafts, "\n\n") + "\n"
+}
+
+// followUpStepID returns the session step ID of the search j of the follow-up i.
+func followUpStepID(i, j int) string {
+	return fmt.Sprintf("followup-%d-%d", i+1, j+1)
+}
@@ -0,0 +1,106 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dimdasci/seek/internal/llm"
+	"github.com/dimdasci/seek/internal/models"
+)
+
+var (
+	// ErrSearchFailed is returned when the web search of a step fails.
+	ErrSearchFailed = errors.New("web search failed")
+	// ErrPagesFailed is returned when no page of the search results can be read.
+	ErrPagesFailed = errors.New("all pages failed to load")
+	// ErrAnalysisFailed is returned when no page of a step can be analysed.
+	ErrAnalysisFailed = errors.New("all page analyses failed")
+	// ErrNoFindings is returned when there are no findings to compile.
+	ErrNoFindings = errors.New("no findings to compile")
+	// ErrOverBudget is returned for the work skipped to stay within the run budget.
+	ErrOverBudget = errors.New("skipped to stay within the budget")
+)
+
+// reportStepID is the outcome ID of the final report writing.
+const reportStepID = "report"
+
+// Result represents the result of a research run.
+type Result struct {
+	Report string               // Final report with references
+	Steps  []models.StepOutcome // Outcomes of the steps in the plan order
+}
+
+// Partial reports whether some steps of the run failed or were skipped.
+func (r *Result) Partial() bool {
+	return !completed(r.Steps)
+}
+
+// newOutcome returns the outcome of the step finished with the error.
+func newOutcome(id, topic string, err error) models.StepOutcome {
+	outcome := models.StepOutcome{
+		ID:     id,
+		Topic:  topic,
+		Status: models.StepCompleted,
+	}
+	if err == nil {
+		return outcome
+	}
+
+	outcome.Status = models.StepFailed
+	outcome.Error = err.Error()
+	switch {
+	case errors.Is(err, ErrOverBudget):
+		outcome.Status = models.StepSkipped
+		outcome.Reason = models.ReasonBudget
+	case errors.Is(err, llm.ErrRefused):
+		outcome.Reason = models.ReasonRefused
+	case errors.Is(err, llm.ErrTruncated):
+		outcome.Reason = models.ReasonTruncated
+	case errors.Is(err, ErrSearchFailed):
+		outcome.Reason = models.ReasonSearchFailed
+	case errors.Is(err, ErrPagesFailed):
+		outcome.Reason = models.ReasonPagesFailed
+	case errors.Is(err, ErrAnalysisFailed):
+		outcome.Reason = models.ReasonAnalysisFailed
+	case errors.Is(err, ErrNoFindings):
+		outcome.Reason = models.ReasonNoFindings
+	default:
+		outcome.Reason = models.ReasonError
+	}
+	return outcome
+}
+
+// completed reports whether all the steps are completed in full.
+func completed(outcomes []models.StepOutcome) bool {
+	for _, outcome := range outcomes {
+		if !outcome.Completed() {
+			return false
+		}
+	}
+	return true
+}
+
+// appendIncomplete appends the section listing the steps not completed in full
+// with their reasons to the report. The report is returned as is if all steps are completed.
+func appendIncomplete(report string, outcomes []models.StepOutcome) string {
+	var b strings.Builder
+	for _, outcome := range outcomes {
+		if !outcome.Completed() {
+			fmt.Fprintf(&b, "- %s: %s, %s\n", outcome.Topic, outcome.Status, outcome.Error)
+		}
+	}
+	if b.Len() == 0 {
+		return report
+	}
+	return strings.TrimRight(report, "\n") + "\n\n## Incomplete research\n\n" +
+		"The report is based on the completed steps only, the following steps are not completed:\n\n" +
+		b.String()
+}
+
+// budgetReport returns the report of a run whose budget ran out before any findings,
+// with the usage of the run.
+func (s *Service) budgetReport(query string) string {
+	return fmt.Sprintf("# %s\n\nThe budget was exhausted before any findings were gathered.\n\n%s",
+		query, strings.TrimSpace(s.meter.Summary()))
+}
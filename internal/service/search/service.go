@@ -2,88 +2,273 @@ package search
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
-	"github.com/dimdasci/seek/internal/client/openai"
+	"github.com/dimdasci/seek/internal/llm"
 	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/progress"
 	"github.com/dimdasci/seek/internal/service/webread"
 	"github.com/dimdasci/seek/internal/service/websearch"
+	"github.com/dimdasci/seek/internal/session"
+	"github.com/dimdasci/seek/internal/usage"
 	"go.uber.org/zap"
 )
 
 type Service struct {
-	openaiClient *openai.Client
-	searcher     websearch.WebSearcher
-	reader       webread.WebReader
-	logger       *zap.Logger
+	llm         llm.LLM
+	searcher    websearch.WebSearcher
+	reader      webread.WebReader
+	verifier    Verifier
+	prefilter   Prefilter
+	observer    progress.Observer
+	logger      *zap.Logger
+	concurrency int          // Max number of search steps executed in parallel
+	deepening   Deepening    // Limits of the follow-up searches on thin findings
+	meter       *usage.Meter // Budget of the run, nil for unlimited runs
 }
 
+// Deepening limits the refined searches of a topic whose findings do not answer its request.
+type Deepening struct {
+	MaxDepth   int // Max rounds of refined searches, 0 disables deepening
+	MaxQueries int // Max refined search queries per topic
+}
+
+// Verifier checks the claims of a report against the cited sources.
+// The pages analysed by the run are passed by URL, so the claims are checked
+// against the same content.
+type Verifier interface {
+	Verify(ctx context.Context, report string, sources []models.Source, pages map[string]models.Page) (*models.Verification, error)
+}
+
+// Prefilter drops the pages irrelevant to the request before the model analyses them.
+// Content of the returned pages can be reduced to the parts relevant to the request.
+type Prefilter interface {
+	Filter(ctx context.Context, request string, pages []models.Page) ([]models.Page, error)
+}
+
+// research holds the state of a single search run.
+type research struct {
+	session *session.Session  // Persisted progress of the run
+	sources *sourceRegistry   // Pages fetched during the run
+	stream  func(text string) // Receives the final report as it is written, nil if not streamed
+}
+
+// NewService creates a new search service.
+// The verifier is optional, the report claims are not checked if it is nil.
+// The prefilter is optional, every page is analysed by the model if it is nil.
+// The observer is optional, it receives the progress events of the runs.
+// The meter is optional, the run is not limited by a budget if it is nil.
 func NewService(
-	openaiClient *openai.Client,
+	llmClient llm.LLM,
 	searcher websearch.WebSearcher,
 	reader webread.WebReader,
-	logger *zap.Logger) *Service {
+	verifier Verifier,
+	prefilter Prefilter,
+	observer progress.Observer,
+	logger *zap.Logger,
+	concurrency int,
+	deepening Deepening,
+	meter *usage.Meter) *Service {
+	if concurrency < 1 {
+		concurrency = 1
+	}
 	return &Service{
-		openaiClient: openaiClient,
-		searcher:     searcher,
-		reader:       reader,
-		logger:       logger,
+		llm:         llmClient,
+		searcher:    searcher,
+		reader:      reader,
+		verifier:    verifier,
+		prefilter:   prefilter,
+		observer:    observer,
+		logger:      logger,
+		concurrency: concurrency,
+		deepening:   deepening,
+		meter:       meter,
 	}
 }
 
-func (s *Service) Search(ctx context.Context, query string) (string, error) {
+// Search answers the question of the session.
+// Progress is saved to the session after every completed operation, and the work
+// already stored in the session is reused, so a failed run can be resumed.
+// A report written without some of the steps lists them in a closing section,
+// such a session runs the steps not completed again on resume.
+// The final report of a complex plan is written to the stream as it is generated,
+// with the citations numbered as in the returned report. The stream is optional.
+func (s *Service) Search(ctx context.Context, sess *session.Session, stream io.Writer) (*Result, error) {
 	s.logger.Info("Service: searching for answer",
-		zap.String("query", query))
+		zap.String("session", sess.ID),
+		zap.String("query", sess.Question))
 
-	fmt.Println("Building search plan...")
-	p, err := s.openaiClient.PlanSearch(ctx, query)
-	if err != nil {
-		s.logger.Error("Service: failed to search for answer",
-			zap.Error(err))
-		return "", fmt.Errorf("failed to search answer: %w", err)
+	if sess.Report != "" && completed(sess.Outcomes) {
+		s.logger.Info("Service: session is already completed")
+		return &Result{Report: sess.Report, Steps: sess.Outcomes}, nil
 	}
 
+	p := sess.Plan
 	if p == nil {
-		s.logger.Error("Service: search plan is nil")
-		return "", fmt.Errorf("search plan is nil")
+		s.emit(progress.Event{Type: progress.Planning, Message: "Building search plan..."})
+		var err error
+		p, err = s.llm.PlanSearch(ctx, sess.Question)
+		if err != nil {
+			s.logger.Error("Service: failed to search for answer",
+				zap.Error(err))
+			return nil, fmt.Errorf("failed to search answer: %w", err)
+		}
+
+		if p == nil {
+			s.logger.Error("Service: search plan is nil")
+			return nil, fmt.Errorf("search plan is nil")
+		}
+
+		if err := sess.SetPlan(p); err != nil {
+			return nil, err
+		}
 	}
 
 	if !p.Approved {
 		s.logger.Error("Service: search plan is not approved",
 			zap.String("reason", p.Reason))
-		return "", fmt.Errorf("search plan is not approved: %s", p.Reason)
+		return nil, fmt.Errorf("search plan is not approved: %w: %s", llm.ErrRefused, p.Reason)
 	}
 
-	fmt.Printf("Going to perform %s search\n", p.SearchComplexity)
+	r := &research{
+		session: sess,
+		sources: newSourceRegistry(sess.Sources),
+	}
+	var cs *citationStream
+	if stream != nil {
+		cs = newCitationStream(stream, r.sources, s.logger)
+		r.stream = cs.write
+	}
 
-	report, err := s.executePlan(ctx, p)
-	if err != nil {
-		s.logger.Error("Service: failed to execute search plan",
-			zap.Error(err))
-		return "", fmt.Errorf("failed to execute search plan: %w", err)
+	draft, outcomes := sess.Draft, sess.Outcomes
+	if draft == "" || !completed(outcomes) {
+		s.emit(progress.Event{
+			Type:    progress.PlanBuilt,
+			Steps:   max(len(p.SearchPlan), 1),
+			Message: fmt.Sprintf("Going to perform %s search", p.SearchComplexity),
+		})
+
+		var err error
+		draft, outcomes, err = s.executePlan(ctx, r, p)
+		if cs != nil {
+			cs.flush()
+		}
+		if err != nil {
+			s.logger.Error("Service: failed to execute search plan",
+				zap.Error(err))
+			return nil, fmt.Errorf("failed to execute search plan: %w", err)
+		}
+		if err := sess.SetDraft(draft, outcomes); err != nil {
+			return nil, err
+		}
+	}
+	report, cited := s.renderCitations(draft, r.sources)
+
+	if s.verifier != nil && s.meter.Level() == usage.LevelExhausted {
+		s.emit(progress.Event{Type: progress.VerifySkipped, Message: "Skipping claim verification, budget is exhausted"})
+		s.meter.Note("skipped claim verification")
+	} else if s.verifier != nil {
+		s.emit(progress.Event{Type: progress.Verifying, Message: "Verifying claims..."})
+		verification, err := s.verifier.Verify(ctx, report, cited, sess.Pages())
+		if err != nil {
+			s.logger.Error("Service: failed to verify report",
+				zap.Error(err))
+			return nil, fmt.Errorf("failed to verify report: %w", err)
+		}
+		if err := sess.SetVerification(verification); err != nil {
+			return nil, err
+		}
+		report = strings.TrimRight(report, "\n") + "\n\n" + verification.Markdown()
+	}
+	report = appendIncomplete(report, outcomes)
+
+	if err := sess.SetReport(report); err != nil {
+		return nil, err
 	}
-	return report, nil
+
+	return &Result{Report: report, Steps: outcomes}, nil
 }
 
-func (s *Service) executePlan(ctx context.Context, plan *models.Plan) (string, error) {
+// executePlan executes the search plan.
+// It returns the report citing source IDs and the outcomes of the plan steps.
+func (s *Service) executePlan(ctx context.Context, r *research, plan *models.Plan) (string, []models.StepOutcome, error) {
 	if plan == nil {
-		return "", fmt.Errorf("search plan is nil")
+		return "", nil, fmt.Errorf("search plan is nil")
 	}
 
 	// execute simple search
 	var notes string
+	var outcomes []models.StepOutcome
 	switch plan.SearchComplexity {
 	case "simple":
-		notes = fmt.Sprintf(
-			"# %s\n\n%s",
-			plan.SearchQuery,
-			s.executeSimpleSearch(ctx, plan.SearchQuery, plan.SearchQuery, plan.CompilationPolicy))
+		saved := r.session.Step(simpleStepID)
+		findings := saved.Topic
+		if !saved.Done {
+			s.emit(progress.Event{
+				Type:    progress.StepStarted,
+				Step:    1,
+				StepID:  simpleStepID,
+				Topic:   plan.SearchQuery,
+				Query:   plan.SearchQuery,
+				Message: fmt.Sprintf("Searching for %s", plan.SearchQuery),
+			})
+			var err error
+			findings, err = s.executeSimpleSearch(ctx, r, simpleStepID,
+				plan.SearchQuery, plan.SearchQuery, plan.CompilationPolicy)
+			outcome := newOutcome(simpleStepID, plan.SearchQuery, err)
+			s.emitStepDone("Step", 0, outcome)
+			// the budget ran out before the only step, the run ends with what it has
+			if errors.Is(err, ErrOverBudget) {
+				return s.budgetReport(plan.SearchQuery), []models.StepOutcome{outcome}, nil
+			}
+			// the only step has failed, there is nothing to report
+			if err != nil {
+				return "", nil, fmt.Errorf("search step failed: %w", err)
+			}
+		}
+		if findings == "" {
+			findings = "No relevant information was found."
+		}
+		notes = fmt.Sprintf("# %s\n\n%s", plan.SearchQuery, findings)
+		outcomes = []models.StepOutcome{newOutcome(simpleStepID, plan.SearchQuery, nil)}
 
 	case "complex":
-		notes = s.executeComplexSearch(ctx, plan)
+		var err error
+		if notes, outcomes, err = s.executeComplexSearch(ctx, r, plan); err != nil {
+			return "", nil, err
+		}
 	default:
-		return "", fmt.Errorf("unknown search complexity: %s", plan.SearchComplexity)
+		return "", nil, fmt.Errorf("unknown search complexity: %s", plan.SearchComplexity)
+	}
+
+	// keep the partial progress in the session instead of a report of an interrupted run
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
 	}
 
-	return notes, nil
+	return notes, outcomes, nil
+}
+
+// emit sends the progress event to the observer, if any.
+func (s *Service) emit(event progress.Event) {
+	if s.observer == nil {
+		return
+	}
+	event.Time = time.Now()
+	s.observer.Observe(event)
+}
+
+// saveStep applies the update to the session step.
+// A failed save is logged only, it affects the resume but not the current run.
+func (s *Service) saveStep(r *research, id string, update func(step *session.Step)) {
+	if err := r.session.UpdateStep(id, update); err != nil {
+		s.logger.Error("Service: failed to save session",
+			zap.String("session", r.session.ID),
+			zap.String("step", id),
+			zap.Error(err))
+	}
 }
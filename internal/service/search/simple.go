@@ -3,56 +3,423 @@ package search
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
 
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/progress"
+	"github.com/dimdasci/seek/internal/session"
+	"github.com/dimdasci/seek/internal/usage"
 	"go.uber.org/zap"
 )
 
+// simpleStepID is the session step ID of a simple search plan.
+const simpleStepID = "simple"
+
 // executeSimpleSearch performs a simple search for the given query.
-// It returns a string with the search results.
+// When the compiled findings do not answer the request, refined queries are
+// searched in deepening rounds limited by the service deepening settings.
+// Deepening stops when a round adds no key points.
+// Deepening is skipped when the run budget is running out.
+// Search results, pages, page analyses and findings stored in the session step are reused.
+// Failed refined searches are logged only, the findings are compiled without them.
+// It returns a string with the search results, or an error if the step fails.
 func (s *Service) executeSimpleSearch(
 	ctx context.Context,
+	r *research,
+	stepID string,
 	topic string,
 	query string,
 	policy string,
-) string {
+) (string, error) {
 	s.logger.Debug("Simple search",
+		zap.String("step", stepID),
 		zap.String("query", query),
 		zap.String("policy", policy))
 
-	// perform web search
-	results, err := s.searcher.Search(ctx, query)
+	seen := make(map[string]bool)
+	keyPoints, err := s.searchKeyPoints(ctx, r, stepID, topic, query, policy, seen)
 	if err != nil {
-		s.logger.Error("Service: failed to search for answer",
-			zap.Error(err))
-		return fmt.Sprintf("Failed to search for %s", query)
+		return "", err
 	}
 
-	urls := make([]string, 0, len(results))
-	for _, result := range results {
-		urls = append(urls, result.URL)
+	saved := r.session.Step(stepID)
+	queries := []string{query}
+	var answer string
+	for round := 0; ; round++ {
+		// compile findings of the round, nothing to compile if no page is relevant
+		if round < len(saved.Findings) {
+			answer = saved.Findings[round]
+		} else {
+			answer = ""
+			if keyPoints != "" {
+				s.logger.Info("Compiling results",
+					zap.String("request", topic),
+					zap.Int("round", round))
+				answer, err = s.llm.CompileFindings(ctx, keyPoints, topic, policy)
+				if err != nil {
+					// the step is compiled again on resume
+					return "", err
+				}
+			}
+			s.saveStep(r, stepID, func(step *session.Step) {
+				step.Findings = append(step.Findings, answer)
+			})
+		}
+
+		if round >= s.deepening.MaxDepth || len(queries) > s.deepening.MaxQueries {
+			break
+		}
+
+		// assess the findings and search for the missing information
+		var gap models.Gap
+		if round < len(saved.Gaps) {
+			gap = saved.Gaps[round]
+		} else {
+			assessed, err := s.llm.AssessFindings(ctx, topic, policy, answer, queries)
+			if err != nil {
+				s.logger.Error("Service: failed to assess findings",
+					zap.String("topic", topic),
+					zap.Error(err))
+				break
+			}
+			gap = *assessed
+			s.saveStep(r, stepID, func(step *session.Step) {
+				step.Gaps = append(step.Gaps, gap)
+			})
+		}
+		if gap.Answered || len(gap.Queries) == 0 {
+			break
+		}
+		if s.meter.Level() >= usage.LevelReduced {
+			s.meter.Note("skipped deeper searches")
+			break
+		}
+
+		s.emit(progress.Event{
+			Type:    progress.Deepening,
+			StepID:  stepID,
+			Topic:   topic,
+			Message: fmt.Sprintf("Searching deeper on %s: %s", topic, gap.Missing),
+		})
+		found := len(keyPoints)
+		for k, refined := range gap.Queries {
+			if len(queries) > s.deepening.MaxQueries {
+				break
+			}
+			queries = append(queries, refined)
+			if !slices.Contains(saved.Queries, refined) {
+				s.saveStep(r, stepID, func(step *session.Step) {
+					step.Queries = append(step.Queries, refined)
+				})
+			}
+
+			points, err := s.searchKeyPoints(ctx, r, deepeningStepID(stepID, round, k), topic, refined, policy, seen)
+			if err != nil {
+				s.logger.Warn("Service: refined search failed",
+					zap.String("query", refined),
+					zap.Error(err))
+				continue
+			}
+			keyPoints += points
+		}
+
+		// the findings of the same key points are not compiled again
+		if len(keyPoints) == found {
+			s.logger.Info("Service: refined searches found nothing new",
+				zap.String("topic", topic),
+				zap.Int("round", round))
+			break
+		}
 	}
 
-	s.logger.Info("Service: read web pages",
-		zap.Int("pages", len(urls)))
+	s.saveStep(r, stepID, func(step *session.Step) {
+		step.Done = true
+		step.Topic = answer
+	})
+
+	return answer, nil
+}
+
+// searchKeyPoints searches the web for the query, reads the pages and gathers
+// the key points of the pages relevant to the topic. Pages with URLs in seen are
+// not analysed again, the URLs of the new pages are added to seen.
+// Search results, pages and page analyses are stored in the session step.
+// It returns a string with the key points.
+func (s *Service) searchKeyPoints(
+	ctx context.Context,
+	r *research,
+	stepID string,
+	topic string,
+	query string,
+	policy string,
+	seen map[string]bool,
+) (string, error) {
+	saved := r.session.Step(stepID)
 
-	pages, err := s.reader.Read(ctx, urls)
+	// perform web search
+	results := saved.Results
+	if !saved.Searched {
+		var err error
+		results, err = s.searcher.Search(ctx, query)
+		if err != nil {
+			s.logger.Error("Service: failed to search for answer",
+				zap.Error(err))
+			return "", fmt.Errorf("%w: %w", ErrSearchFailed, err)
+		}
+		s.saveStep(r, stepID, func(step *session.Step) {
+			step.Searched = true
+			step.Results = results
+		})
+	}
+
+	pages := saved.Pages
+	if !saved.Read {
+		urls := make([]string, 0, len(results))
+		for _, result := range results {
+			urls = append(urls, result.URL)
+		}
+
+		// read fewer pages when the budget is running out
+		allowed := s.meter.AllowPages(len(urls))
+		if allowed == 0 && len(urls) > 0 {
+			return "", ErrOverBudget
+		}
+		if allowed < len(urls) {
+			s.meter.Note("analysed fewer pages")
+			urls = urls[:allowed]
+		}
+
+		s.logger.Info("Service: read web pages",
+			zap.Int("pages", len(urls)))
+
+		read, err := s.reader.Read(ctx, urls)
+		if err != nil {
+			s.logger.Error("Service: failed to read web pages",
+				zap.Error(err))
+			return "", fmt.Errorf("%w: %w", ErrPagesFailed, err)
+		}
+
+		s.logger.Debug("Service: read web pages",
+			zap.Int("pages", len(read.Pages)),
+			zap.Int("errors", len(read.Errors)))
+		s.emit(progress.Event{
+			Type:    progress.PagesFetched,
+			StepID:  stepID,
+			Topic:   topic,
+			Query:   query,
+			Pages:   len(read.Pages),
+			Failed:  len(read.Errors),
+			Message: fmt.Sprintf("Read %d pages for %s", len(read.Pages), query),
+		})
+
+		for _, page := range read.Errors {
+			s.logger.Error("Service: failed to read web page",
+				zap.String("url", page.URL),
+				zap.String("error", page.Error))
+		}
+
+		// the pages are read again on resume
+		if len(read.Pages) == 0 && len(read.Errors) > 0 {
+			return "", fmt.Errorf("%w: %d pages, first error: %s",
+				ErrPagesFailed, len(read.Errors), read.Errors[0].Error)
+		}
+
+		// assign source IDs the LLM cites the pages with
+		pages = read.Pages
+		r.sources.register(pages)
+
+		// sources are saved first, so the restored registry knows every saved page
+		if err := r.session.SetSources(r.sources.list()); err != nil {
+			s.logger.Error("Service: failed to save session sources",
+				zap.Error(err))
+		}
+		s.saveStep(r, stepID, func(step *session.Step) {
+			step.Read = true
+			step.Pages = pages
+		})
+	}
+
+	fresh := make([]models.Page, 0, len(pages))
+	for _, page := range pages {
+		if !seen[page.URL] {
+			seen[page.URL] = true
+			fresh = append(fresh, page)
+		}
+	}
+
+	return s.gatherKeyPoints(ctx, r, stepID, saved.Analyses, fresh, topic, policy)
+}
+
+// deepeningStepID returns the session step ID of the refined query k
+// searched in the deepening round of the step.
+func deepeningStepID(stepID string, round, k int) string {
+	return fmt.Sprintf("%s/%d.%d", stepID, round+1, k+1)
+}
+
+// gatherKeyPoints gathers key points from relevant pages.
+// Pages are analysed in parallel, except those with an analysis in the session
+// and those dropped by the prefilter.
+// It returns a string with the key points from all relevant pages in the page order,
+// or an error if every page analysed in the call fails.
+func (s *Service) gatherKeyPoints(
+	ctx context.Context,
+	r *research,
+	stepID string,
+	analyses map[string]models.PageAnalysis,
+	pages []models.Page,
+	request string,
+	instructions string,
+) (string, error) {
+	keyPoints := make([]string, len(pages))
+	pages, dropped := s.prefilterPages(ctx, r, stepID, analyses, pages, request)
+
+	var (
+		mu       sync.Mutex
+		analysed int   // Pages analysed in the call
+		failed   int   // Pages failed to analyse
+		lastErr  error // Error of the last failed analysis
+	)
+	var wg sync.WaitGroup
+	for i, p := range pages {
+		if analysis, ok := analyses[p.URL]; ok {
+			if analysis.Relevant {
+				keyPoints[i] = analysis.KeyPoints
+			}
+			continue
+		}
+		if dropped[p.URL] {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, p models.Page) {
+			defer wg.Done()
+			relevant, points, err := s.llm.AnalyzePage(ctx, &p, &request, &instructions)
+
+			mu.Lock()
+			analysed++
+			if err != nil {
+				failed++
+				lastErr = err
+			}
+			mu.Unlock()
+
+			event := progress.Event{
+				Type:     progress.PageAnalysed,
+				StepID:   stepID,
+				Topic:    request,
+				URL:      p.URL,
+				Relevant: relevant,
+				Message:  fmt.Sprintf("Analysed %s", p.URL),
+			}
+			if err != nil {
+				event.Error = err.Error()
+			}
+			s.emit(event)
+
+			if err != nil {
+				s.logger.Error("failed to analyze page",
+					zap.Error(err),
+					zap.String("url", p.URL),
+					zap.String("title", p.Title),
+					zap.Int("page_index", i))
+				return
+			}
+
+			s.saveStep(r, stepID, func(step *session.Step) {
+				if step.Analyses == nil {
+					step.Analyses = make(map[string]models.PageAnalysis)
+				}
+				step.Analyses[p.URL] = models.PageAnalysis{
+					URL:       p.URL,
+					Relevant:  relevant,
+					KeyPoints: points,
+				}
+			})
+
+			if relevant {
+				keyPoints[i] = points
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	if analysed > 0 && failed == analysed {
+		return "", fmt.Errorf("%w: %d pages, last error: %w", ErrAnalysisFailed, failed, lastErr)
+	}
+
+	var compilation string
+	for _, points := range keyPoints {
+		if points != "" {
+			compilation += points + "\n\n"
+		}
+	}
+	return compilation, nil
+}
+
+// prefilterPages drops the pages without an analysis in the session that the
+// prefilter finds irrelevant to the request. Dropped pages are saved as irrelevant.
+// It returns the pages with the content to analyse and the URLs of the dropped pages.
+// All pages are kept if the prefilter is not set or fails.
+func (s *Service) prefilterPages(
+	ctx context.Context,
+	r *research,
+	stepID string,
+	analyses map[string]models.PageAnalysis,
+	pages []models.Page,
+	request string,
+) ([]models.Page, map[string]bool) {
+	if s.prefilter == nil {
+		return pages, nil
+	}
+
+	fresh := make([]models.Page, 0, len(pages))
+	for _, p := range pages {
+		if _, ok := analyses[p.URL]; !ok && p.Content != "" {
+			fresh = append(fresh, p)
+		}
+	}
+	if len(fresh) == 0 {
+		return pages, nil
+	}
+
+	kept, err := s.prefilter.Filter(ctx, request, fresh)
 	if err != nil {
-		s.logger.Error("Service: failed to read web pages",
+		s.logger.Warn("Service: failed to prefilter pages, analysing all pages",
 			zap.Error(err))
-		return fmt.Sprintf("Failed to read web pages: %v", err)
+		return pages, nil
 	}
 
-	s.logger.Debug("Service: read web pages",
-		zap.Int("pages", len(pages.Pages)),
-		zap.Int("errors", len(pages.Errors)))
+	content := make(map[string]string, len(kept))
+	for _, p := range kept {
+		content[p.URL] = p.Content
+	}
 
-	for _, page := range pages.Errors {
-		s.logger.Error("Service: failed to read web page",
-			zap.String("url", page.URL),
-			zap.String("error", page.Error))
+	filtered := make([]models.Page, len(pages))
+	dropped := make(map[string]bool)
+	for i, p := range pages {
+		filtered[i] = p
+		if _, ok := analyses[p.URL]; ok || p.Content == "" {
+			continue
+		}
+		if text, ok := content[p.URL]; ok {
+			filtered[i].Content = text
+		} else {
+			dropped[p.URL] = true
+		}
 	}
 
-	answer := s.openaiClient.CompileResults(ctx, pages.Pages, &topic, &policy)
+	if len(dropped) > 0 {
+		s.saveStep(r, stepID, func(step *session.Step) {
+			if step.Analyses == nil {
+				step.Analyses = make(map[string]models.PageAnalysis)
+			}
+			for url := range dropped {
+				step.Analyses[url] = models.PageAnalysis{URL: url}
+			}
+		})
+	}
 
-	return answer
+	return filtered, dropped
 }
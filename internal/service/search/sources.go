@@ -0,0 +1,210 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/dimdasci/seek/internal/models"
+	"go.uber.org/zap"
+)
+
+var (
+	// Match citation markers with one or more source ids, e.g. [S1] or [S1, S3],
+	// with the leading space to drop it together with stripped markers
+	citationRegex = regexp.MustCompile(`[ \t]?\[\s*(S\d+(?:\s*[,;]\s*S\d+)*)\s*\]`)
+	// Match a single source id inside a citation marker
+	sourceIDRegex = regexp.MustCompile(`S\d+`)
+	// Escape brackets of titles used as markdown link text
+	linkTextEscaper = strings.NewReplacer("[", "\\[", "]", "\\]")
+)
+
+// sourceRegistry assigns stable IDs to the pages fetched during a search run.
+// A URL fetched several times keeps the ID of the first fetch.
+type sourceRegistry struct {
+	mu      sync.Mutex
+	byURL   map[string]string        // source ID by page URL
+	sources map[string]models.Source // sources by ID
+}
+
+// newSourceRegistry creates a source registry restoring the saved sources.
+func newSourceRegistry(saved []models.Source) *sourceRegistry {
+	r := &sourceRegistry{
+		byURL:   make(map[string]string),
+		sources: make(map[string]models.Source),
+	}
+	for _, source := range saved {
+		r.byURL[source.URL] = source.ID
+		r.sources[source.ID] = source
+	}
+	return r
+}
+
+// register assigns source IDs to the pages.
+func (r *sourceRegistry) register(pages []models.Page) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range pages {
+		id, ok := r.byURL[pages[i].URL]
+		if !ok {
+			id = fmt.Sprintf("S%d", len(r.sources)+1)
+			r.byURL[pages[i].URL] = id
+			r.sources[id] = models.Source{
+				ID:    id,
+				URL:   pages[i].URL,
+				Title: pages[i].Title,
+			}
+		}
+		pages[i].ID = id
+	}
+}
+
+// list returns the registered sources in order of the ID assignment.
+func (r *sourceRegistry) list() []models.Source {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list := make([]models.Source, 0, len(r.sources))
+	for i := 1; i <= len(r.sources); i++ {
+		list = append(list, r.sources[fmt.Sprintf("S%d", i)])
+	}
+	return list
+}
+
+// lookup returns the source with the ID.
+func (r *sourceRegistry) lookup(id string) (models.Source, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	source, ok := r.sources[id]
+	return source, ok
+}
+
+// citations numbers the cited sources in order of the first citation.
+type citations struct {
+	sources *sourceRegistry
+	logger  *zap.Logger
+	numbers map[string]int  // citation number by source ID
+	cited   []models.Source // cited sources in order of the numbers
+}
+
+// newCitations creates a new citation numbering of the registered sources.
+func newCitations(sources *sourceRegistry, logger *zap.Logger) *citations {
+	return &citations{
+		sources: sources,
+		logger:  logger,
+		numbers: make(map[string]int),
+	}
+}
+
+// replace replaces source IDs cited in the text with numbered [n] markers.
+// Citations of unknown sources are stripped.
+func (c *citations) replace(text string) string {
+	return citationRegex.ReplaceAllStringFunc(text, func(marker string) string {
+		var b strings.Builder
+		for _, id := range sourceIDRegex.FindAllString(marker, -1) {
+			source, ok := c.sources.lookup(id)
+			if !ok {
+				c.logger.Warn("Stripped citation of unknown source",
+					zap.String("source_id", id))
+				continue
+			}
+			n, ok := c.numbers[id]
+			if !ok {
+				c.cited = append(c.cited, source)
+				n = len(c.cited)
+				c.numbers[id] = n
+			}
+			fmt.Fprintf(&b, "[%d]", n)
+		}
+		if b.Len() == 0 {
+			return ""
+		}
+		return marker[:strings.Index(marker, "[")] + b.String()
+	})
+}
+
+// renderCitations replaces source IDs cited in the report with numbered
+// [n] markers and appends the bibliography of the cited sources.
+// Sources are numbered in order of the first citation.
+// Citations of unknown sources are stripped.
+// It returns the report with citations and the cited sources.
+func (s *Service) renderCitations(report string, sources *sourceRegistry) (string, []models.Source) {
+	c := newCitations(sources, s.logger)
+	report = c.replace(report)
+
+	if len(c.cited) == 0 {
+		return report, nil
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(report, "\n"))
+	b.WriteString("\n\n## References\n\n")
+	for i, source := range c.cited {
+		title := source.Title
+		if title == "" {
+			title = source.URL
+		}
+		fmt.Fprintf(&b, "%d. [%s](%s)\n", i+1, linkTextEscaper.Replace(title), source.URL)
+	}
+
+	return b.String(), c.cited
+}
+
+// citationStream writes a streamed report with the citations numbered as
+// renderCitations numbers them. Text that can still turn into a citation
+// marker is held back until the marker is complete.
+type citationStream struct {
+	w         io.Writer
+	citations *citations
+	pending   string // text held back
+}
+
+// newCitationStream creates a new citation stream writing to w.
+func newCitationStream(w io.Writer, sources *sourceRegistry, logger *zap.Logger) *citationStream {
+	return &citationStream{
+		w:         w,
+		citations: newCitations(sources, logger),
+	}
+}
+
+// write writes the text replacing the completed citation markers.
+func (cs *citationStream) write(text string) {
+	cs.pending += text
+	n := markerStart(cs.pending)
+	if n == 0 {
+		return
+	}
+	io.WriteString(cs.w, cs.citations.replace(cs.pending[:n]))
+	cs.pending = cs.pending[n:]
+}
+
+// flush writes the text held back.
+func (cs *citationStream) flush() {
+	if cs.pending != "" {
+		io.WriteString(cs.w, cs.citations.replace(cs.pending))
+		cs.pending = ""
+	}
+}
+
+// markerStart returns the position of the incomplete citation marker
+// at the end of the text, the text length if there is none.
+func markerStart(text string) int {
+	start := strings.LastIndex(text, "[")
+	if start < 0 || strings.ContainsAny(text[start:], "]") ||
+		strings.TrimLeft(text[start+1:], "S0123456789,; \t\r\n\f\v") != "" {
+		// a trailing blank can precede a marker
+		if strings.HasSuffix(text, " ") || strings.HasSuffix(text, "\t") {
+			return len(text) - 1
+		}
+		return len(text)
+	}
+	// the blank before a marker is dropped with a stripped marker
+	if start > 0 && (text[start-1] == ' ' || text[start-1] == '\t') {
+		start--
+	}
+	return start
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/dimdasci/seek/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestCitationsReplace(t *testing.T) {
+	registry := newSourceRegistry([]models.Source{
+		{ID: "S1", URL: "https://a.com"},
+		{ID: "S2", URL: "https://b.com"},
+		{ID: "S3", URL: "https://c.com"},
+	})
+
+	tests := []struct {
+		name  string
+		text  string
+		want  string
+		cited []string // URLs of the cited sources in order of the numbers
+	}{
+		{
+			name:  "numbers in order of the first citation",
+			text:  "First [S2]. Second [S1]. Again [S2].",
+			want:  "First [1]. Second [2]. Again [1].",
+			cited: []string{"https://b.com", "https://a.com"},
+		},
+		{
+			name:  "several sources in one marker",
+			text:  "Claim [S3, S1].",
+			want:  "Claim [1][2].",
+			cited: []string{"https://c.com", "https://a.com"},
+		},
+		{
+			name:  "semicolon and inner spaces",
+			text:  "Claim [ S1; S2 ].",
+			want:  "Claim [1][2].",
+			cited: []string{"https://a.com", "https://b.com"},
+		},
+		{
+			name: "unknown source is stripped with its space",
+			text: "Claim [S9].",
+			want: "Claim.",
+		},
+		{
+			name:  "unknown source is dropped from a marker",
+			text:  "Claim [S9, S2].",
+			want:  "Claim [1].",
+			cited: []string{"https://b.com"},
+		},
+		{
+			name: "other brackets are kept",
+			text: "See [the docs](https://d.com) and [1].",
+			want: "See [the docs](https://d.com) and [1].",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCitations(registry, zap.NewNop())
+			if got := c.replace(tt.text); got != tt.want {
+				t.Errorf("replace() = %q, want %q", got, tt.want)
+			}
+
+			var cited []string
+			for _, source := range c.cited {
+				cited = append(cited, source.URL)
+			}
+			if !slices.Equal(cited, tt.cited) {
+				t.Errorf("cited = %v, want %v", cited, tt.cited)
+			}
+		})
+	}
+}
+
+func TestSourceRegistryRegister(t *testing.T) {
+	registry := newSourceRegistry([]models.Source{{ID: "S1", URL: "https://a.com"}})
+
+	pages := []models.Page{
+		{URL: "https://b.com"},
+		{URL: "https://a.com"},
+		{URL: "https://b.com"},
+	}
+	registry.register(pages)
+
+	ids := make([]string, 0, len(pages))
+	for _, page := range pages {
+		ids = append(ids, page.ID)
+	}
+	if want := []string{"S2", "S1", "S2"}; !slices.Equal(ids, want) {
+		t.Errorf("page ids = %v, want %v", ids, want)
+	}
+	if got := len(registry.list()); got != 2 {
+		t.Errorf("registered %d sources, want 2", got)
+	}
+}
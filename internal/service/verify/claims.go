@@ -0,0 +1,83 @@
+package verify
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Match numbered citation markers, e.g. [1]
+	markerRegex = regexp.MustCompile(`\[(\d+)\]`)
+	// Match citation markers with the leading space to remove them from claims
+	markerSpaceRegex = regexp.MustCompile(`\s?\[\d+\]`)
+	// Match list markers at the line start, e.g. "- ", "* " or "1. "
+	listRegex = regexp.MustCompile(`^\s*(?:[-*+]|\d+\.)\s+`)
+	// Match sentence ends: punctuation, optional citation markers and a space or the text end
+	sentenceEndRegex = regexp.MustCompile(`[.!?](?:\s?\[\d+\])*(?:\s+|$)`)
+)
+
+// claim is a sentence of the report with the citations it carries.
+type claim struct {
+	text string // Sentence without citation markers
+	refs []int  // Numbers of the cited sources
+}
+
+// extractClaims splits the report into sentences and returns the ones citing sources.
+// Headings, tables and the references section are skipped.
+func extractClaims(report string) []claim {
+	var claims []claim
+
+	for _, line := range strings.Split(report, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "## References") {
+			break
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "|") {
+			continue
+		}
+		line = listRegex.ReplaceAllString(line, "")
+
+		for _, sentence := range splitSentences(line) {
+			refs := markerRefs(sentence)
+			if len(refs) == 0 {
+				continue
+			}
+			text := strings.TrimSpace(markerSpaceRegex.ReplaceAllString(sentence, ""))
+			text = strings.Join(strings.Fields(text), " ")
+			claims = append(claims, claim{text: text, refs: refs})
+		}
+	}
+
+	return claims
+}
+
+// splitSentences splits the text at sentence ends keeping citation markers
+// that follow the punctuation with the sentence.
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for _, loc := range sentenceEndRegex.FindAllStringIndex(text, -1) {
+		sentences = append(sentences, text[start:loc[1]])
+		start = loc[1]
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+	return sentences
+}
+
+// markerRefs returns the unique source numbers cited in the text.
+func markerRefs(text string) []int {
+	var refs []int
+	seen := make(map[int]bool)
+	for _, m := range markerRegex.FindAllStringSubmatch(text, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || seen[n] {
+			continue
+		}
+		seen[n] = true
+		refs = append(refs, n)
+	}
+	return refs
+}
@@ -0,0 +1,61 @@
+package verify
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractClaims(t *testing.T) {
+	tests := []struct {
+		name   string
+		report string
+		want   []claim
+	}{
+		{
+			name:   "sentences citing sources",
+			report: "The wall was built in 1961 [1]. It fell in 1989 [2][3]. No source here.",
+			want: []claim{
+				{text: "The wall was built in 1961.", refs: []int{1}},
+				{text: "It fell in 1989.", refs: []int{2, 3}},
+			},
+		},
+		{
+			name:   "markers after the punctuation",
+			report: "First claim. [1] Second claim! [2]",
+			want: []claim{
+				{text: "First claim.", refs: []int{1}},
+				{text: "Second claim!", refs: []int{2}},
+			},
+		},
+		{
+			name:   "repeated source is counted once",
+			report: "Claim [2] with more [2].",
+			want:   []claim{{text: "Claim with more.", refs: []int{2}}},
+		},
+		{
+			name:   "list items",
+			report: "- Item one [1]\n1. Item two [2]",
+			want: []claim{
+				{text: "Item one", refs: []int{1}},
+				{text: "Item two", refs: []int{2}},
+			},
+		},
+		{
+			name:   "headings, tables and references are skipped",
+			report: "# Title [1]\n\n| a | b [1] |\n\nBody [1].\n\n## References\n\n1. [Source](https://a.com) [2].",
+			want:   []claim{{text: "Body.", refs: []int{1}}},
+		},
+		{
+			name:   "no citations",
+			report: "Nothing is cited here.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractClaims(tt.report); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractClaims() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
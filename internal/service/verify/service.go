@@ -0,0 +1,137 @@
+// Package verify provides a service to check report claims against the cited sources.
+package verify
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dimdasci/seek/internal/llm"
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/service/webread"
+	"go.uber.org/zap"
+)
+
+// Service checks the claims of a report against the pages of the cited sources.
+// Pages are taken from the pages of the run first, then from the reader cache
+// when the reader provides one.
+type Service struct {
+	llm         llm.LLM
+	reader      webread.WebReader
+	logger      *zap.Logger
+	concurrency int // Max number of claims checked in parallel
+}
+
+// NewService creates a new verification service.
+func NewService(
+	llmClient llm.LLM,
+	reader webread.WebReader,
+	logger *zap.Logger,
+	concurrency int) *Service {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Service{
+		llm:         llmClient,
+		reader:      reader,
+		logger:      logger,
+		concurrency: concurrency,
+	}
+}
+
+// Verify splits the report into claims and checks every claim citing sources.
+// Citation [n] of the report refers to sources[n-1], its content is taken
+// from the pages by URL if present.
+// A claim that cannot be checked is marked as unchecked with the reason.
+func (s *Service) Verify(ctx context.Context, report string, sources []models.Source, pages map[string]models.Page) (*models.Verification, error) {
+	claims := extractClaims(report)
+	s.logger.Info("Verifying report claims", zap.Int("claims", len(claims)))
+
+	verification := &models.Verification{Claims: make([]models.Claim, len(claims))}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.concurrency)
+	for i, c := range claims {
+		wg.Add(1)
+		go func(i int, c claim) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			verification.Claims[i] = s.verifyClaim(ctx, c, sources, pages)
+		}(i, c)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return verification, nil
+}
+
+// verifyClaim checks a single claim against its cited sources.
+func (s *Service) verifyClaim(ctx context.Context, c claim, sources []models.Source, stored map[string]models.Page) models.Claim {
+	result := models.Claim{Text: c.text}
+
+	pages := make([]models.Page, 0, len(c.refs))
+	for _, ref := range c.refs {
+		if ref < 1 || ref > len(sources) {
+			s.logger.Warn("Claim cites unknown source",
+				zap.String("claim", c.text),
+				zap.Int("ref", ref))
+			continue
+		}
+		url := sources[ref-1].URL
+		result.Sources = append(result.Sources, url)
+
+		page, ok := stored[url]
+		if !ok {
+			page, ok = s.page(ctx, url)
+		}
+		if !ok {
+			continue
+		}
+		pages = append(pages, page)
+	}
+
+	if len(pages) == 0 {
+		result.Verdict = models.VerdictUnchecked
+		result.Explanation = "Content of the cited sources is not available."
+		return result
+	}
+
+	verdict, explanation, err := s.llm.VerifyClaim(ctx, c.text, pages)
+	if err != nil {
+		s.logger.Error("Failed to verify claim",
+			zap.String("claim", c.text),
+			zap.Error(err))
+		result.Verdict = models.VerdictUnchecked
+		result.Explanation = "The model failed to check the claim."
+		return result
+	}
+
+	result.Verdict = verdict
+	result.Explanation = explanation
+	return result
+}
+
+// page returns the page of the URL from the reader cache,
+// or reads it again if the reader has no cache or missed the page.
+func (s *Service) page(ctx context.Context, url string) (models.Page, bool) {
+	if cache, ok := s.reader.(webread.PageCache); ok {
+		if page, ok := cache.Cached(url); ok {
+			return page, true
+		}
+	}
+
+	s.logger.Debug("Page not cached, reading again", zap.String("url", url))
+	pages, err := s.reader.Read(ctx, []string{url})
+	if err != nil || len(pages.Pages) == 0 {
+		s.logger.Error("Failed to read cited page",
+			zap.String("url", url),
+			zap.Error(err))
+		return models.Page{}, false
+	}
+	return pages.Pages[0], true
+}
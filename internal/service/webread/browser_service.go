@@ -16,6 +16,7 @@ import (
 	"github.com/dimdasci/seek/internal/service/filewriter"
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
 	"go.uber.org/zap"
 	"golang.org/x/net/html"
 )
@@ -24,7 +25,7 @@ import (
 type BrowserReadService struct {
 	logger       *zap.Logger
 	timeout      time.Duration
-	cache        sync.Map
+	cache        *pageCache
 	tagsToRemove map[string]struct{}
 	browser      *rod.Browser
 }
@@ -145,6 +146,7 @@ func NewBrowserReadService(logger *zap.Logger, timeout time.Duration) (*BrowserR
 		},
 		timeout: timeout,
 		browser: browser,
+		cache:   newPageCache(cacheSize, cacheTTL),
 	}, nil
 }
 
@@ -162,14 +164,19 @@ func (b *BrowserReadService) Read(ctx context.Context, urls []string) (*models.W
 		go func(url string) {
 			defer wg.Done()
 
-			if cached, ok := b.cache.Load(url); ok {
+			if cached, ok := b.cache.load(url); ok {
 				b.logger.Debug("Returning cached result", zap.String("url", url))
-				results <- cached.(models.Page)
+				results <- cached
 				return
 			}
 
 			// Create a new page
-			page := b.browser.MustPage()
+			page, err := b.browser.Page(proto.TargetCreateTarget{})
+			if err != nil {
+				b.logger.Error("Failed to create browser page", zap.String("url", url), zap.Error(err))
+				errors <- models.PageError{URL: url, Error: err.Error()}
+				return
+			}
 			defer page.Close()
 
 			// Set timeout for navigation
@@ -272,7 +279,7 @@ func (b *BrowserReadService) Read(ctx context.Context, urls []string) (*models.W
 			}
 
 			results <- result
-			b.cache.Store(url, result)
+			b.cache.store(result)
 		}(url)
 	}
 
@@ -291,6 +298,11 @@ func (b *BrowserReadService) Read(ctx context.Context, urls []string) (*models.W
 	return &webPages, nil
 }
 
+// Cached returns the page read before from the URL.
+func (b *BrowserReadService) Cached(url string) (models.Page, bool) {
+	return b.cache.load(url)
+}
+
 // removeUnwantedTags removes unwanted tags from an HTML node and returns the cleaned node.
 func (b *BrowserReadService) removeUnwantedTags(n *html.Node) *html.Node {
 	if n == nil {
@@ -0,0 +1,80 @@
+package webread
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/dimdasci/seek/internal/models"
+)
+
+// Limits of the page cache of a reader. The readers of a long-running server
+// are shared by all the runs, so the cache keeps the recent pages only.
+const (
+	cacheSize = 512       // Max number of cached pages
+	cacheTTL  = time.Hour // Time a page is cached
+)
+
+// pageCache keeps the pages read recently by URL. It drops the least recently
+// used pages beyond its size and the pages older than its TTL.
+type pageCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List               // Entries from the most to the least recently used
+	entries map[string]*list.Element // Entries by URL
+}
+
+// cacheEntry is a cached page with the time it was stored.
+type cacheEntry struct {
+	page   models.Page
+	stored time.Time
+}
+
+// newPageCache creates a new page cache with the size and TTL.
+func newPageCache(size int, ttl time.Duration) *pageCache {
+	return &pageCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// load returns the cached page of the URL.
+func (c *pageCache) load(url string) (models.Page, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[url]
+	if !ok {
+		return models.Page{}, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Since(entry.stored) > c.ttl {
+		c.order.Remove(element)
+		delete(c.entries, url)
+		return models.Page{}, false
+	}
+	c.order.MoveToFront(element)
+	return entry.page, true
+}
+
+// store caches the page by its URL, dropping the least recently used pages beyond the size.
+func (c *pageCache) store(page models.Page) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{page: page, stored: time.Now()}
+	if element, ok := c.entries[page.URL]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[page.URL] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).page.URL)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/retry"
 	"go.uber.org/zap"
 )
 
@@ -18,7 +19,7 @@ type ReaderFactory struct {
 	standardReader *ReadService
 }
 
-func NewReaderFactory(logger *zap.Logger, timeout time.Duration, minContentLen int) (*ReaderFactory, error) {
+func NewReaderFactory(logger *zap.Logger, timeout time.Duration, minContentLen int, policy retry.Policy) (*ReaderFactory, error) {
 	browserReader, err := NewBrowserReadService(logger, timeout)
 	if err != nil {
 		return nil, err
@@ -29,7 +30,7 @@ func NewReaderFactory(logger *zap.Logger, timeout time.Duration, minContentLen i
 		timeout:        timeout,
 		minContentLen:  minContentLen,
 		browserReader:  browserReader,
-		standardReader: NewReadService(logger, timeout),
+		standardReader: NewReadService(logger, timeout, policy),
 	}, nil
 }
 
@@ -84,8 +85,15 @@ func (f *fallbackReader) Read(ctx context.Context, urls []string) (*models.WebPa
 				// Try fallback reader
 				result, err = f.fallback.Read(ctx, []string{url})
 				if err != nil || result == nil || len(result.Pages) != 1 {
-					f.logger.Error("Both readers failed", zap.String("url", url), zap.Error(err))
-					errors <- models.PageError{URL: url, Error: err.Error()}
+					// the fallback reports the page failures in the result, not in the error
+					pageErr := models.PageError{URL: url, Error: "no content read"}
+					if err != nil {
+						pageErr.Error = err.Error()
+					} else if result != nil && len(result.Errors) > 0 {
+						pageErr = result.Errors[0]
+					}
+					f.logger.Error("Both readers failed", zap.String("url", url), zap.String("error", pageErr.Error))
+					errors <- pageErr
 					return
 				}
 				f.logger.Info("Fallback reader succeeded", zap.String("url", url),
@@ -121,6 +129,26 @@ func (f *fallbackReader) Read(ctx context.Context, urls []string) (*models.WebPa
 	return &webPages, nil
 }
 
+// Cached returns the page read before from the URL.
+// The primary reader page is preferred unless it is shorter than the minimal content length.
+func (f *fallbackReader) Cached(url string) (models.Page, bool) {
+	var primary, fallback models.Page
+	var primaryOK, fallbackOK bool
+	if cache, ok := f.primary.(PageCache); ok {
+		primary, primaryOK = cache.Cached(url)
+	}
+	if primaryOK && len(primary.Content) >= f.minContentLen {
+		return primary, true
+	}
+	if cache, ok := f.fallback.(PageCache); ok {
+		fallback, fallbackOK = cache.Cached(url)
+	}
+	if fallbackOK {
+		return fallback, true
+	}
+	return primary, primaryOK
+}
+
 func (f *ReaderFactory) Close() error {
 	return f.browserReader.Close()
 }
@@ -10,3 +10,8 @@ import (
 type WebReader interface {
 	Read(ctx context.Context, urls []string) (*models.WebPages, error)
 }
+
+// PageCache provides access to the pages already read.
+type PageCache interface {
+	Cached(url string) (models.Page, bool)
+}
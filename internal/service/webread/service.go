@@ -11,6 +11,7 @@ import (
 
 	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
 	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/retry"
 	"go.uber.org/zap"
 	"golang.org/x/net/html"
 )
@@ -20,10 +21,11 @@ type ReadService struct {
 	logger       *zap.Logger
 	tagsToRemove map[string]struct{}
 	timeout      time.Duration
-	cache        sync.Map
+	client       *http.Client // HTTP client retrying the failed requests
+	cache        *pageCache
 }
 
-func NewReadService(logger *zap.Logger, timeout time.Duration) *ReadService {
+func NewReadService(logger *zap.Logger, timeout time.Duration, policy retry.Policy) *ReadService {
 	return &ReadService{
 		logger: logger,
 		tagsToRemove: map[string]struct{}{
@@ -38,6 +40,8 @@ func NewReadService(logger *zap.Logger, timeout time.Duration) *ReadService {
 			"image":  {},
 		},
 		timeout: timeout,
+		client:  retry.NewClient(policy, logger),
+		cache:   newPageCache(cacheSize, cacheTTL),
 	}
 }
 
@@ -50,9 +54,9 @@ func (r *ReadService) Read(ctx context.Context, urls []string) (*models.WebPages
 		wg.Add(1)
 		go func(url string) {
 			defer wg.Done()
-			if cached, ok := r.cache.Load(url); ok {
+			if cached, ok := r.cache.load(url); ok {
 				r.logger.Debug("Returning cached result", zap.String("url", url))
-				results <- cached.(models.Page)
+				results <- cached
 				return
 			}
 			r.logger.Debug("Reading web page", zap.String("url", url))
@@ -111,7 +115,7 @@ func (r *ReadService) Read(ctx context.Context, urls []string) (*models.WebPages
 			}
 			r.logger.Debug("Converted HTML to markdown", zap.String("url", url), zap.String("title", title))
 			results <- models.Page{URL: url, Title: title, Content: markdown}
-			r.cache.Store(url, models.Page{URL: url, Title: title, Content: markdown})
+			r.cache.store(models.Page{URL: url, Title: title, Content: markdown})
 		}(url)
 	}
 
@@ -130,23 +134,32 @@ func (r *ReadService) Read(ctx context.Context, urls []string) (*models.WebPages
 	return &webPages, nil
 }
 
+// Cached returns the page read before from the URL.
+func (r *ReadService) Cached(url string) (models.Page, bool) {
+	return r.cache.load(url)
+}
+
 // fetchHTML fetches the HTML content of the given URL.
 func (r *ReadService) fetchHTML(ctx context.Context, url string) (string, error) {
+	// add timeout to the context, retries stop at its deadline
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", err
 	}
 
-	client := &http.Client{
-		Timeout: r.timeout,
-	}
-
-	res, err := client.Do(req)
+	res, err := r.client.Do(req)
 	if err != nil {
 		return "", err
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
 	buf := new(bytes.Buffer)
 	buf.ReadFrom(res.Body)
 	return buf.String(), nil
@@ -9,6 +9,7 @@ import (
 	"net/http"
 
 	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/retry"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
@@ -18,14 +19,17 @@ type TavilyReadService struct {
 	APIKey  string
 	BaseURL string
 	logger  *zap.Logger
+	client  *http.Client
 }
 
-// NewTavilyReadService creates a new instance of TavilyReadService.
-func NewTavilyReadService(logger *zap.Logger) *TavilyReadService {
+// NewTavilyReadService creates a new instance of TavilyReadService
+// retrying the failed requests by the policy.
+func NewTavilyReadService(logger *zap.Logger, policy retry.Policy) *TavilyReadService {
 	return &TavilyReadService{
 		APIKey:  viper.GetString("websearch.tavily.api_key"),
 		BaseURL: viper.GetString("websearch.tavily.extract_url"),
 		logger:  logger,
+		client:  retry.NewClient(policy, logger),
 	}
 }
 
@@ -50,8 +54,7 @@ func (t *TavilyReadService) Read(ctx context.Context, urls []string) (*models.We
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+t.APIKey)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := t.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
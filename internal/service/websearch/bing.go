@@ -0,0 +1,91 @@
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/retry"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// bingMaxCount is the max number of results returned by Bing Web Search per request.
+const bingMaxCount = 50
+
+// BingSearchService provides web search with the Bing Web Search API.
+type BingSearchService struct {
+	APIKey     string
+	BaseURL    string
+	Market     string
+	MaxResults int
+	logger     *zap.Logger
+	timeout    time.Duration
+	client     *http.Client
+}
+
+// NewBingSearchService creates a new instance of BingSearchService
+// retrying the failed requests by the policy.
+func NewBingSearchService(logger *zap.Logger, timeout time.Duration, policy retry.Policy) *BingSearchService {
+	return &BingSearchService{
+		APIKey:     viper.GetString("websearch.bing.api_key"),
+		BaseURL:    viper.GetString("websearch.bing.search_url"),
+		Market:     viper.GetString("websearch.bing.market"),
+		MaxResults: viper.GetInt("websearch.bing.max_results"),
+		logger:     logger,
+		timeout:    timeout,
+		client:     retry.NewClient(policy, logger),
+	}
+}
+
+// bingResponse represents the response from Bing Web Search API.
+type bingResponse struct {
+	WebPages struct {
+		Value []struct {
+			Name    string `json:"name"`
+			URL     string `json:"url"`
+			Snippet string `json:"snippet"`
+		} `json:"value"`
+	} `json:"webPages"`
+}
+
+// Search performs a web search using the Bing Web Search API.
+// It returns the search results, or an error.
+func (s *BingSearchService) Search(ctx context.Context, query string) ([]models.SearchResult, error) {
+	count := s.MaxResults
+	if count <= 0 || count > bingMaxCount {
+		count = bingMaxCount
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("count", strconv.Itoa(count))
+	params.Set("responseFilter", "Webpages")
+	if s.Market != "" {
+		params.Set("mkt", s.Market)
+	}
+	endpoint := fmt.Sprintf("%s?%s", s.BaseURL, params.Encode())
+	s.logger.Debug("Request URL", zap.String("url", endpoint))
+
+	var resp bingResponse
+	err := getJSON(ctx, s.client, s.logger, s.timeout, endpoint,
+		map[string]string{"Ocp-Apim-Subscription-Key": s.APIKey}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.SearchResult, 0, len(resp.WebPages.Value))
+	for _, r := range resp.WebPages.Value {
+		results = append(results, models.SearchResult{
+			Title:   r.Name,
+			URL:     r.URL,
+			Content: r.Snippet,
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,85 @@
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/retry"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// braveMaxCount is the max number of results returned by Brave Search per request.
+const braveMaxCount = 20
+
+// BraveSearchService provides web search with the Brave Search API.
+type BraveSearchService struct {
+	APIKey     string
+	BaseURL    string
+	MaxResults int
+	logger     *zap.Logger
+	timeout    time.Duration
+	client     *http.Client
+}
+
+// NewBraveSearchService creates a new instance of BraveSearchService
+// retrying the failed requests by the policy.
+func NewBraveSearchService(logger *zap.Logger, timeout time.Duration, policy retry.Policy) *BraveSearchService {
+	return &BraveSearchService{
+		APIKey:     viper.GetString("websearch.brave.api_key"),
+		BaseURL:    viper.GetString("websearch.brave.search_url"),
+		MaxResults: viper.GetInt("websearch.brave.max_results"),
+		logger:     logger,
+		timeout:    timeout,
+		client:     retry.NewClient(policy, logger),
+	}
+}
+
+// braveResponse represents the response from Brave Search API.
+type braveResponse struct {
+	Web struct {
+		Results []struct {
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			Description string `json:"description"`
+		} `json:"results"`
+	} `json:"web"`
+}
+
+// Search performs a web search using the Brave Search API.
+// It returns the search results, or an error.
+func (s *BraveSearchService) Search(ctx context.Context, query string) ([]models.SearchResult, error) {
+	count := s.MaxResults
+	if count <= 0 || count > braveMaxCount {
+		count = braveMaxCount
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("count", strconv.Itoa(count))
+	endpoint := fmt.Sprintf("%s?%s", s.BaseURL, params.Encode())
+	s.logger.Debug("Request URL", zap.String("url", endpoint))
+
+	var resp braveResponse
+	err := getJSON(ctx, s.client, s.logger, s.timeout, endpoint,
+		map[string]string{"X-Subscription-Token": s.APIKey}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.SearchResult, 0, len(resp.Web.Results))
+	for _, r := range resp.Web.Results {
+		results = append(results, models.SearchResult{
+			Title:   r.Title,
+			URL:     r.URL,
+			Content: r.Description,
+		})
+	}
+
+	return results, nil
+}
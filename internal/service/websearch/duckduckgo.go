@@ -0,0 +1,195 @@
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/retry"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"golang.org/x/net/html"
+)
+
+// duckDuckGoUserAgent is sent with requests, the HTML endpoint rejects empty agents.
+const duckDuckGoUserAgent = "Mozilla/5.0 (compatible; seek/1.0)"
+
+// DuckDuckGoSearchService provides web search with the DuckDuckGo HTML endpoint.
+type DuckDuckGoSearchService struct {
+	BaseURL    string
+	Region     string
+	MaxResults int
+	logger     *zap.Logger
+	timeout    time.Duration
+	client     *http.Client
+}
+
+// NewDuckDuckGoSearchService creates a new instance of DuckDuckGoSearchService
+// retrying the failed requests by the policy.
+func NewDuckDuckGoSearchService(logger *zap.Logger, timeout time.Duration, policy retry.Policy) *DuckDuckGoSearchService {
+	return &DuckDuckGoSearchService{
+		BaseURL:    viper.GetString("websearch.duckduckgo.search_url"),
+		Region:     viper.GetString("websearch.duckduckgo.region"),
+		MaxResults: viper.GetInt("websearch.duckduckgo.max_results"),
+		logger:     logger,
+		timeout:    timeout,
+		client:     retry.NewClient(policy, logger),
+	}
+}
+
+// Search performs a web search by parsing the DuckDuckGo HTML results page.
+// It returns the search results, or an error.
+func (s *DuckDuckGoSearchService) Search(ctx context.Context, query string) ([]models.SearchResult, error) {
+	form := url.Values{}
+	form.Set("q", query)
+	if s.Region != "" {
+		form.Set("kl", s.Region)
+	}
+
+	// add timeout to the context
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.BaseURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", duckDuckGoUserAgent)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	s.logger.Debug("Response status", zap.Int("status", resp.StatusCode))
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	doc, err := html.Parse(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	results := s.parseResults(doc)
+	if s.MaxResults > 0 && len(results) > s.MaxResults {
+		results = results[:s.MaxResults]
+	}
+
+	return results, nil
+}
+
+// parseResults extracts search results from the HTML results page.
+// Every result is a div with the result class holding the result__a link
+// and the result__snippet text. Ads are skipped.
+func (s *DuckDuckGoSearchService) parseResults(doc *html.Node) []models.SearchResult {
+	var results []models.SearchResult
+
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "div" &&
+			hasClass(n, "result") && !hasClass(n, "result--ad") {
+			if result, ok := s.parseResult(n); ok {
+				results = append(results, result)
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+
+	return results
+}
+
+// parseResult extracts a single search result from the result node.
+func (s *DuckDuckGoSearchService) parseResult(n *html.Node) (models.SearchResult, bool) {
+	var result models.SearchResult
+
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch {
+			case hasClass(n, "result__a"):
+				result.Title = textContent(n)
+				result.URL = resultURL(attr(n, "href"))
+			case hasClass(n, "result__snippet"):
+				result.Content = textContent(n)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(n)
+
+	if result.URL == "" {
+		s.logger.Debug("Skipping result without URL", zap.String("title", result.Title))
+		return result, false
+	}
+	return result, true
+}
+
+// resultURL resolves the DuckDuckGo redirect link to the target URL.
+func resultURL(href string) string {
+	if strings.HasPrefix(href, "//") {
+		href = "https:" + href
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if target := u.Query().Get("uddg"); target != "" {
+		return target
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return href
+}
+
+// hasClass reports whether the node has the class.
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// attr returns the value of the node attribute.
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// textContent returns the text of the node and its children with collapsed spaces.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
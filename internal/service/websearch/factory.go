@@ -0,0 +1,58 @@
+package websearch
+
+import (
+	"fmt"
+
+	"github.com/dimdasci/seek/internal/retry"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// NewWebSearcher creates the web searcher for the provider.
+// Provider settings are read from the websearch.<provider> config section.
+// Failed requests of the provider are retried by the policy.
+func NewWebSearcher(logger *zap.Logger, provider string, policy retry.Policy) (WebSearcher, error) {
+	timeout := viper.GetDuration(fmt.Sprintf("websearch.%s.timeout", provider))
+
+	switch provider {
+	case "tavily":
+		return NewTavilySearchService(logger, timeout, policy), nil
+	case "searxng":
+		return NewSearXNGSearchService(logger, timeout, policy), nil
+	case "brave":
+		return NewBraveSearchService(logger, timeout, policy), nil
+	case "bing":
+		return NewBingSearchService(logger, timeout, policy), nil
+	case "google":
+		return NewGoogleSearchService(logger, timeout, policy), nil
+	case "duckduckgo":
+		return NewDuckDuckGoSearchService(logger, timeout, policy), nil
+	case "fusion":
+		return newFusionSearcher(logger, policy)
+	default:
+		return nil, fmt.Errorf("unknown web search provider: %s", provider)
+	}
+}
+
+// newFusionSearcher creates the fusion searcher over the providers
+// listed in websearch.fusion.providers.
+func newFusionSearcher(logger *zap.Logger, policy retry.Policy) (WebSearcher, error) {
+	providers := viper.GetStringSlice("websearch.fusion.providers")
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no providers configured for fusion search")
+	}
+
+	searchers := make([]NamedSearcher, 0, len(providers))
+	for _, provider := range providers {
+		if provider == "fusion" {
+			return nil, fmt.Errorf("fusion search cannot include itself")
+		}
+		searcher, err := NewWebSearcher(logger, provider, policy)
+		if err != nil {
+			return nil, err
+		}
+		searchers = append(searchers, NamedSearcher{Name: provider, Searcher: searcher})
+	}
+
+	return NewFusionSearchService(logger, searchers, viper.GetInt("websearch.fusion.max_results")), nil
+}
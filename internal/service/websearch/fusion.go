@@ -0,0 +1,172 @@
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dimdasci/seek/internal/models"
+	"go.uber.org/zap"
+)
+
+// rrfK is the rank constant of reciprocal rank fusion.
+// It dampens the weight of top ranks so that agreement between providers matters more.
+const rrfK = 60
+
+// NamedSearcher is a web searcher with the name of its provider.
+type NamedSearcher struct {
+	Name     string
+	Searcher WebSearcher
+}
+
+// FusionSearchService queries several web searchers concurrently
+// and merges their results with reciprocal rank fusion.
+type FusionSearchService struct {
+	searchers  []NamedSearcher
+	maxResults int
+	logger     *zap.Logger
+}
+
+// NewFusionSearchService creates a new instance of FusionSearchService.
+// maxResults limits the merged list, 0 means no limit.
+func NewFusionSearchService(logger *zap.Logger, searchers []NamedSearcher, maxResults int) *FusionSearchService {
+	return &FusionSearchService{
+		searchers:  searchers,
+		maxResults: maxResults,
+		logger:     logger,
+	}
+}
+
+// fusedResult is a search result with its fusion score.
+type fusedResult struct {
+	result models.SearchResult
+	score  float64
+	order  int // order of the first appearance, breaks score ties
+}
+
+// Search performs the web search with all providers and merges the results.
+// Failed providers are logged and skipped, the search fails only if all providers fail.
+func (s *FusionSearchService) Search(ctx context.Context, query string) ([]models.SearchResult, error) {
+	lists := make([][]models.SearchResult, len(s.searchers))
+	errs := make([]error, len(s.searchers))
+
+	var wg sync.WaitGroup
+	for i, ns := range s.searchers {
+		wg.Add(1)
+		go func(i int, ns NamedSearcher) {
+			defer wg.Done()
+			lists[i], errs[i] = ns.Searcher.Search(ctx, query)
+		}(i, ns)
+	}
+	wg.Wait()
+
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			s.logger.Error("Search provider failed",
+				zap.String("provider", s.searchers[i].Name),
+				zap.String("query", query),
+				zap.Error(err))
+		}
+	}
+	if failed == len(s.searchers) {
+		return nil, fmt.Errorf("all search providers failed: %w", errs[0])
+	}
+
+	// merge lists by normalized URL
+	fused := make(map[string]*fusedResult)
+	for i, list := range lists {
+		if errs[i] != nil {
+			continue
+		}
+		provider := s.searchers[i].Name
+		for rank, r := range list {
+			key := normalizeURL(r.URL)
+			if key == "" {
+				continue
+			}
+			f, ok := fused[key]
+			if !ok {
+				f = &fusedResult{result: r, order: len(fused)}
+				f.result.Providers = nil
+				fused[key] = f
+			}
+			if f.result.Content == "" {
+				f.result.Content = r.Content
+			}
+			if !contains(f.result.Providers, provider) {
+				f.result.Providers = append(f.result.Providers, provider)
+				f.score += 1.0 / float64(rrfK+rank+1)
+			}
+		}
+	}
+
+	merged := make([]*fusedResult, 0, len(fused))
+	for _, f := range fused {
+		merged = append(merged, f)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].score != merged[j].score {
+			return merged[i].score > merged[j].score
+		}
+		return merged[i].order < merged[j].order
+	})
+
+	if s.maxResults > 0 && len(merged) > s.maxResults {
+		merged = merged[:s.maxResults]
+	}
+
+	results := make([]models.SearchResult, 0, len(merged))
+	for _, f := range merged {
+		results = append(results, f.result)
+	}
+
+	s.logger.Debug("Fused search results",
+		zap.String("query", query),
+		zap.Int("providers", len(s.searchers)),
+		zap.Int("failed", failed),
+		zap.Int("unique results", len(fused)),
+		zap.Int("results", len(results)))
+
+	return results, nil
+}
+
+// normalizeURL returns the URL in a form used to detect duplicates:
+// lower case host without www, no fragment, no tracking parameters,
+// sorted query and no trailing slash. It returns empty string for invalid URLs.
+func normalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(key, "utm_") {
+			query.Del(key)
+		}
+	}
+
+	normalized := host + strings.TrimRight(u.EscapedPath(), "/")
+	if len(query) > 0 {
+		// Encode sorts the parameters by key
+		normalized += "?" + query.Encode()
+	}
+	return normalized
+}
+
+// contains reports whether the list contains the value.
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package websearch
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/dimdasci/seek/internal/models"
+	"go.uber.org/zap"
+)
+
+// stubSearcher returns the results for the URLs, or the error.
+type stubSearcher struct {
+	urls []string
+	err  error
+}
+
+func (s stubSearcher) Search(ctx context.Context, query string) ([]models.SearchResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	results := make([]models.SearchResult, 0, len(s.urls))
+	for _, u := range s.urls {
+		results = append(results, models.SearchResult{URL: u})
+	}
+	return results, nil
+}
+
+func TestFusionSearch(t *testing.T) {
+	failed := errors.New("provider failed")
+	tests := []struct {
+		name       string
+		searchers  []stubSearcher
+		maxResults int
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name: "single provider keeps its order",
+			searchers: []stubSearcher{
+				{urls: []string{"https://a.com", "https://b.com", "https://c.com"}},
+			},
+			want: []string{"https://a.com", "https://b.com", "https://c.com"},
+		},
+		{
+			name: "agreement between providers ranks first",
+			searchers: []stubSearcher{
+				{urls: []string{"https://a.com", "https://b.com"}},
+				{urls: []string{"https://c.com", "https://b.com"}},
+			},
+			want: []string{"https://b.com", "https://a.com", "https://c.com"},
+		},
+		{
+			name: "duplicates are merged by normalized url",
+			searchers: []stubSearcher{
+				{urls: []string{"https://www.A.com/page/?utm_source=x"}},
+				{urls: []string{"https://a.com/page#top"}},
+			},
+			want: []string{"https://www.A.com/page/?utm_source=x"},
+		},
+		{
+			name: "ties keep the first appearance order",
+			searchers: []stubSearcher{
+				{urls: []string{"https://a.com"}},
+				{urls: []string{"https://b.com"}},
+			},
+			want: []string{"https://a.com", "https://b.com"},
+		},
+		{
+			name: "merged list is limited",
+			searchers: []stubSearcher{
+				{urls: []string{"https://a.com", "https://b.com", "https://c.com"}},
+			},
+			maxResults: 2,
+			want:       []string{"https://a.com", "https://b.com"},
+		},
+		{
+			name: "failed provider is skipped",
+			searchers: []stubSearcher{
+				{err: failed},
+				{urls: []string{"https://a.com"}},
+			},
+			want: []string{"https://a.com"},
+		},
+		{
+			name: "all providers failed",
+			searchers: []stubSearcher{
+				{err: failed},
+				{err: failed},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searchers := make([]NamedSearcher, 0, len(tt.searchers))
+			for i, s := range tt.searchers {
+				searchers = append(searchers, NamedSearcher{Name: string(rune('a' + i)), Searcher: s})
+			}
+			fusion := NewFusionSearchService(zap.NewNop(), searchers, tt.maxResults)
+
+			results, err := fusion.Search(context.Background(), "query")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Search() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			urls := make([]string, 0, len(results))
+			for _, r := range results {
+				urls = append(urls, r.URL)
+			}
+			if !slices.Equal(urls, tt.want) {
+				t.Errorf("Search() = %v, want %v", urls, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"https://www.Example.com/path/", "example.com/path"},
+		{"http://example.com/path#section", "example.com/path"},
+		{"https://example.com/?b=2&a=1&utm_medium=x", "example.com?a=1&b=2"},
+		{"not a url", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeURL(tt.raw); got != tt.want {
+			t.Errorf("normalizeURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/retry"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	googlePageSize   = 10  // Max number of results returned by Programmable Search per request
+	googleMaxResults = 100 // Max number of results Programmable Search returns for a query
+)
+
+// GoogleSearchService provides web search with the Google Programmable Search JSON API.
+type GoogleSearchService struct {
+	APIKey     string
+	EngineID   string
+	BaseURL    string
+	MaxResults int
+	logger     *zap.Logger
+	timeout    time.Duration
+	client     *http.Client
+}
+
+// NewGoogleSearchService creates a new instance of GoogleSearchService
+// retrying the failed requests by the policy.
+func NewGoogleSearchService(logger *zap.Logger, timeout time.Duration, policy retry.Policy) *GoogleSearchService {
+	return &GoogleSearchService{
+		APIKey:     viper.GetString("websearch.google.api_key"),
+		EngineID:   viper.GetString("websearch.google.cx"),
+		BaseURL:    viper.GetString("websearch.google.search_url"),
+		MaxResults: viper.GetInt("websearch.google.max_results"),
+		logger:     logger,
+		timeout:    timeout,
+		client:     retry.NewClient(policy, logger),
+	}
+}
+
+// googleResponse represents the response from Programmable Search JSON API.
+type googleResponse struct {
+	Items []struct {
+		Title   string `json:"title"`
+		Link    string `json:"link"`
+		Snippet string `json:"snippet"`
+	} `json:"items"`
+}
+
+// Search performs a web search using the Programmable Search JSON API.
+// Results beyond the page size are requested page by page, up to the API limit
+// of 100 results. A failed page after the first one ends the search with the
+// results collected so far.
+// It returns the search results, or an error.
+func (s *GoogleSearchService) Search(ctx context.Context, query string) ([]models.SearchResult, error) {
+	maxResults := s.MaxResults
+	if maxResults <= 0 {
+		maxResults = googlePageSize
+	}
+	maxResults = min(maxResults, googleMaxResults)
+
+	results := make([]models.SearchResult, 0, maxResults)
+	for start := 1; len(results) < maxResults && start <= googleMaxResults; start += googlePageSize {
+		// the last requested result must stay within the API limit
+		num := min(maxResults-len(results), googlePageSize, googleMaxResults-start+1)
+
+		params := url.Values{}
+		params.Set("key", s.APIKey)
+		params.Set("cx", s.EngineID)
+		params.Set("q", query)
+		params.Set("num", strconv.Itoa(num))
+		params.Set("start", strconv.Itoa(start))
+		endpoint := fmt.Sprintf("%s?%s", s.BaseURL, params.Encode())
+		s.logger.Debug("Request", zap.String("query", query), zap.Int("start", start), zap.Int("num", num))
+
+		var resp googleResponse
+		if err := getJSON(ctx, s.client, s.logger, s.timeout, endpoint, nil, &resp); err != nil {
+			if start == 1 {
+				return nil, err
+			}
+			s.logger.Warn("Failed to get the next results page",
+				zap.String("query", query),
+				zap.Int("start", start),
+				zap.Error(err))
+			break
+		}
+
+		for _, r := range resp.Items {
+			results = append(results, models.SearchResult{
+				Title:   r.Title,
+				URL:     r.Link,
+				Content: r.Snippet,
+			})
+		}
+
+		// no more results available
+		if len(resp.Items) < num {
+			break
+		}
+	}
+
+	return results, nil
+}
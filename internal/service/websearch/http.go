@@ -0,0 +1,75 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// getJSON performs a GET request to the URL with the headers by the client
+// and decodes the JSON response into out.
+func getJSON(
+	ctx context.Context,
+	client *http.Client,
+	logger *zap.Logger,
+	timeout time.Duration,
+	url string,
+	headers map[string]string,
+	out interface{},
+) error {
+	// add timeout to the context
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to perform request: %w", redactQuery(err))
+	}
+	defer resp.Body.Close()
+
+	logger.Debug("Response status", zap.Int("status", resp.StatusCode))
+	logger.Debug("Response headers", zap.Any("headers", resp.Header))
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// redactQuery drops the query from the request URL of the error,
+// some providers take the API key as a query parameter.
+func redactQuery(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			u.RawQuery = ""
+			urlErr.URL = u.String()
+		} else {
+			urlErr.URL = ""
+		}
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/retry"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// SearXNGSearchService provides web search with a self-hosted SearXNG instance.
+// The instance must have the json format enabled in its search settings.
+type SearXNGSearchService struct {
+	BaseURL    string
+	Categories string
+	Language   string
+	MaxResults int
+	logger     *zap.Logger
+	timeout    time.Duration
+	client     *http.Client
+}
+
+// NewSearXNGSearchService creates a new instance of SearXNGSearchService
+// retrying the failed requests by the policy.
+func NewSearXNGSearchService(logger *zap.Logger, timeout time.Duration, policy retry.Policy) *SearXNGSearchService {
+	return &SearXNGSearchService{
+		BaseURL:    strings.TrimRight(viper.GetString("websearch.searxng.url"), "/"),
+		Categories: viper.GetString("websearch.searxng.categories"),
+		Language:   viper.GetString("websearch.searxng.language"),
+		MaxResults: viper.GetInt("websearch.searxng.max_results"),
+		logger:     logger,
+		timeout:    timeout,
+		client:     retry.NewClient(policy, logger),
+	}
+}
+
+// searxngResponse represents the JSON response from SearXNG.
+type searxngResponse struct {
+	Results []struct {
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+// Search performs a web search using the SearXNG JSON API.
+// It returns the search results, or an error.
+func (s *SearXNGSearchService) Search(ctx context.Context, query string) ([]models.SearchResult, error) {
+	if s.BaseURL == "" {
+		return nil, fmt.Errorf("searxng url is not configured")
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("format", "json")
+	if s.Categories != "" {
+		params.Set("categories", s.Categories)
+	}
+	if s.Language != "" {
+		params.Set("language", s.Language)
+	}
+	endpoint := fmt.Sprintf("%s/search?%s", s.BaseURL, params.Encode())
+	s.logger.Debug("Request URL", zap.String("url", endpoint))
+
+	var resp searxngResponse
+	if err := getJSON(ctx, s.client, s.logger, s.timeout, endpoint, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.SearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		if s.MaxResults > 0 && len(results) == s.MaxResults {
+			break
+		}
+		results = append(results, models.SearchResult{
+			Title:   r.Title,
+			URL:     r.URL,
+			Content: r.Content,
+		})
+	}
+
+	return results, nil
+}
@@ -9,7 +9,8 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/dimdasci/seek/internal/models" // Adjust the import path accordingly
+	"github.com/dimdasci/seek/internal/models"
+	"github.com/dimdasci/seek/internal/retry"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
@@ -20,15 +21,18 @@ type TavilySearchService struct {
 	BaseURL string
 	logger  *zap.Logger
 	timeout time.Duration
+	client  *http.Client
 }
 
-// NewTavilySearchService creates a new instance of TavilySearchService.
-func NewTavilySearchService(logger *zap.Logger, timeout time.Duration) *TavilySearchService {
+// NewTavilySearchService creates a new instance of TavilySearchService
+// retrying the failed requests by the policy.
+func NewTavilySearchService(logger *zap.Logger, timeout time.Duration, policy retry.Policy) *TavilySearchService {
 	return &TavilySearchService{
 		APIKey:  viper.GetString("websearch.tavily.api_key"),
 		BaseURL: viper.GetString("websearch.tavily.search_url"),
 		logger:  logger,
 		timeout: timeout,
+		client:  retry.NewClient(policy, logger),
 	}
 }
 
@@ -64,8 +68,7 @@ func (s *TavilySearchService) Search(ctx context.Context, query string) ([]model
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.APIKey))
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := s.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform request: %w", err)
 	}
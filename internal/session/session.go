@@ -0,0 +1,218 @@
+// Package session persists search runs on disk to resume them after a failure.
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dimdasci/seek/internal/models"
+)
+
+// Session represents the state of a search run.
+// Every update is saved to the session file, so a failed run can be resumed
+// without repeating the completed searches and model calls.
+type Session struct {
+	mu   sync.Mutex
+	path string // Session file
+
+	ID           string               `json:"id"`
+	Question     string               `json:"question"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	Plan         *models.Plan         `json:"plan,omitempty"`         // Approved search plan
+	Steps        map[string]*Step     `json:"steps,omitempty"`        // Step progress by step ID
+	Sources      []models.Source      `json:"sources,omitempty"`      // Sources in order of the ID assignment
+	Draft        string               `json:"draft,omitempty"`        // Report citing source IDs
+	Outcomes     []models.StepOutcome `json:"outcomes,omitempty"`     // Step outcomes of the draft run
+	Verification *models.Verification `json:"verification,omitempty"` // Claims check of the draft, nil if not verified
+	Report       string               `json:"report,omitempty"`       // Final report with references
+	FollowUps    []*FollowUp          `json:"follow_ups,omitempty"`   // Follow-up questions in order
+}
+
+// FollowUp represents a follow-up question answered in the session.
+type FollowUp struct {
+	Question string               `json:"question"`
+	Plan     *models.FollowUpPlan `json:"plan,omitempty"`     // Decision how to answer the question
+	Draft    string               `json:"draft,omitempty"`    // Answer citing source IDs
+	Outcomes []models.StepOutcome `json:"outcomes,omitempty"` // Outcomes of the follow-up searches
+}
+
+// Step represents the progress of a single plan step.
+type Step struct {
+	Searched bool                           `json:"searched"`           // Web search is completed
+	Results  []models.SearchResult          `json:"results,omitempty"`  // Web search results
+	Read     bool                           `json:"read"`               // Pages are fetched
+	Pages    []models.Page                  `json:"pages,omitempty"`    // Fetched pages with source IDs
+	Analyses map[string]models.PageAnalysis `json:"analyses,omitempty"` // Page analyses by URL
+	Findings []string                       `json:"findings,omitempty"` // Findings compiled in every deepening round
+	Gaps     []models.Gap                   `json:"gaps,omitempty"`     // Assessments of the findings of every round
+	Queries  []string                       `json:"queries,omitempty"`  // Refined queries searched in the deepening rounds
+	Done     bool                           `json:"done"`               // Findings are compiled
+	Topic    string                         `json:"topic,omitempty"`    // Compiled findings
+}
+
+// Step returns a copy of the step with the ID.
+// It returns an empty step if the step has not started yet.
+func (s *Session) Step(id string) Step {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	step, ok := s.Steps[id]
+	if !ok {
+		return Step{}
+	}
+
+	c := *step
+	c.Analyses = make(map[string]models.PageAnalysis, len(step.Analyses))
+	for url, analysis := range step.Analyses {
+		c.Analyses[url] = analysis
+	}
+	return c
+}
+
+// Pages returns the pages read by the steps by URL.
+func (s *Session) Pages() map[string]models.Page {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pages := make(map[string]models.Page)
+	for _, step := range s.Steps {
+		for _, page := range step.Pages {
+			pages[page.URL] = page
+		}
+	}
+	return pages
+}
+
+// UpdateStep applies the update to the step with the ID and saves the session.
+func (s *Session) UpdateStep(id string, update func(step *Step)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Steps == nil {
+		s.Steps = make(map[string]*Step)
+	}
+	step, ok := s.Steps[id]
+	if !ok {
+		step = &Step{}
+		s.Steps[id] = step
+	}
+	update(step)
+
+	return s.save()
+}
+
+// SetPlan stores the search plan and saves the session.
+func (s *Session) SetPlan(plan *models.Plan) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Plan = plan
+	return s.save()
+}
+
+// SetSources stores the sources and saves the session.
+func (s *Session) SetSources(sources []models.Source) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Sources = sources
+	return s.save()
+}
+
+// SetDraft stores the report citing source IDs with the step outcomes
+// of the run and saves the session. The claims check of a previous draft is dropped.
+func (s *Session) SetDraft(draft string, outcomes []models.StepOutcome) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Draft = draft
+	s.Outcomes = outcomes
+	s.Verification = nil
+	return s.save()
+}
+
+// SetVerification stores the claims check of the draft and saves the session.
+func (s *Session) SetVerification(verification *models.Verification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Verification = verification
+	return s.save()
+}
+
+// SetReport stores the final report and saves the session.
+func (s *Session) SetReport(report string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Report = report
+	return s.save()
+}
+
+// FollowUp returns the follow-up for the question to answer.
+// An unanswered last follow-up with the same question is continued,
+// otherwise a new follow-up is added and the session saved.
+// It returns a copy of the follow-up and its index.
+func (s *Session) FollowUp(question string) (FollowUp, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n := len(s.FollowUps); n > 0 {
+		last := s.FollowUps[n-1]
+		if last.Question == question && last.Draft == "" {
+			return *last, n - 1, nil
+		}
+	}
+
+	s.FollowUps = append(s.FollowUps, &FollowUp{Question: question})
+	return FollowUp{Question: question}, len(s.FollowUps) - 1, s.save()
+}
+
+// UpdateFollowUp applies the update to the follow-up with the index and saves the session.
+func (s *Session) UpdateFollowUp(i int, update func(followUp *FollowUp)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i < 0 || i >= len(s.FollowUps) {
+		return fmt.Errorf("unknown follow-up %d", i)
+	}
+	update(s.FollowUps[i])
+
+	return s.save()
+}
+
+// save writes the session to its file.
+// The file is replaced atomically, so an interrupted save keeps the previous state.
+// The caller must hold the lock.
+func (s *Session) save() error {
+	s.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), s.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return nil
+}
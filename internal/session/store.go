@@ -0,0 +1,98 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var (
+	// Match valid session IDs to keep the session files inside the store
+	idRegex = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+)
+
+// ErrNotFound is returned when the session does not exist in the store.
+var ErrNotFound = errors.New("session not found")
+
+// Store keeps sessions as JSON files in a directory.
+type Store struct {
+	dir string
+}
+
+// NewStore creates a new session store in the directory.
+// The directory is created on the first save.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Create starts a new session for the question and saves it.
+func (s *Store) Create(question string) (*Session, error) {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sess := &Session{
+		path:      s.path(id),
+		ID:        id,
+		Question:  question,
+		CreatedAt: now,
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if err := sess.save(); err != nil {
+		return nil, err
+	}
+
+	return sess, nil
+}
+
+// Load reads the session with the ID from the store.
+func (s *Store) Load(id string) (*Session, error) {
+	if !idRegex.MatchString(id) {
+		return nil, fmt.Errorf("invalid session id: %q", id)
+	}
+
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	sess := &Session{}
+	if err := json.Unmarshal(data, sess); err != nil {
+		return nil, fmt.Errorf("failed to decode session %s: %w", id, err)
+	}
+	sess.path = s.path(id)
+
+	return sess, nil
+}
+
+// path returns the file of the session with the ID.
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// newID generates a session ID from the current time and a random suffix,
+// e.g. 20250102-150405-a1b2c3.
+func newID() (string, error) {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix), nil
+}
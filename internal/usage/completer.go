@@ -0,0 +1,44 @@
+package usage
+
+import (
+	"context"
+
+	"github.com/dimdasci/seek/internal/llm"
+)
+
+// Completer accounts the tokens of the completions in the meter.
+type Completer struct {
+	next  llm.Completer
+	meter *Meter
+}
+
+// NewCompleter wraps the completer to account its completions in the meter.
+func NewCompleter(next llm.Completer, meter *Meter) *Completer {
+	return &Completer{
+		next:  next,
+		meter: meter,
+	}
+}
+
+// Complete sends the request to the wrapped completer and accounts the response.
+// Completions cut by the token limit are accounted by the request name.
+func (c *Completer) Complete(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	resp, err := c.next.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	c.meter.AddCompletion(resp.Model, resp.PromptTokens, resp.CompletionTokens)
+	if resp.FinishReason == llm.FinishReasonLength {
+		name := req.Name
+		if name == "" {
+			name = string(req.Tier)
+		}
+		c.meter.AddTruncation(name)
+	}
+	return resp, nil
+}
+
+// PromptLimit returns the prompt limit of the wrapped completer.
+func (c *Completer) PromptLimit(tier llm.Tier) int64 {
+	return c.next.PromptLimit(tier)
+}
@@ -0,0 +1,35 @@
+package usage
+
+import (
+	"context"
+
+	"github.com/dimdasci/seek/internal/llm"
+	"github.com/dimdasci/seek/internal/service/relevance"
+)
+
+// Embedder accounts the tokens of the embeddings in the meter.
+type Embedder struct {
+	next  relevance.Embedder
+	meter *Meter
+}
+
+// NewEmbedder wraps the embedder to account its embeddings in the meter.
+func NewEmbedder(next relevance.Embedder, meter *Meter) *Embedder {
+	return &Embedder{
+		next:  next,
+		meter: meter,
+	}
+}
+
+// Embed sends the texts to the wrapped embedder and accounts the response.
+// Local embeddings use no model and are not accounted.
+func (e *Embedder) Embed(ctx context.Context, texts []string) (*llm.Embeddings, error) {
+	embeddings, err := e.next.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if embeddings.Model != "" {
+		e.meter.AddCompletion(embeddings.Model, embeddings.Tokens, 0)
+	}
+	return embeddings, nil
+}
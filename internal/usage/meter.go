@@ -0,0 +1,289 @@
+// Package usage accounts the tokens, cost, pages and time of a research run
+// and checks them against the run budgets.
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the degradation level of a run by the budget used.
+type Level int
+
+const (
+	LevelNormal    Level = iota // Run as planned
+	LevelReduced                // Analyse fewer pages and skip deepening
+	LevelMinimal                // Skip remaining searches, compile the findings only
+	LevelExhausted              // Skip everything but the final report
+)
+
+// Shares of the tightest budget used to switch the levels
+const (
+	reducedShare = 0.5
+	minimalShare = 0.8
+)
+
+// Limits are the budgets of a research run, zero means unlimited.
+type Limits struct {
+	MaxTokens int64         // Max prompt and completion tokens
+	MaxCost   float64       // Max estimated cost in USD
+	MaxPages  int           // Max web pages read
+	MaxTime   time.Duration // Max wall time
+}
+
+// ModelUsage is the usage of a single model.
+type ModelUsage struct {
+	Calls            int     `json:"calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+	Priced           bool    `json:"priced"` // Model has a price in the price table
+}
+
+// Report is the usage of a run at a point of time.
+type Report struct {
+	Calls            int                   `json:"calls"`
+	PromptTokens     int64                 `json:"prompt_tokens"`
+	CompletionTokens int64                 `json:"completion_tokens"`
+	Cost             float64               `json:"cost"`                  // Estimated cost in USD
+	Priced           bool                  `json:"priced"`                // Every model has a price, the cost is complete
+	Pages            int                   `json:"pages"`                 // Web pages read
+	Duration         time.Duration         `json:"duration"`              // Wall time in nanoseconds
+	Models           map[string]ModelUsage `json:"models,omitempty"`      // Usage by model
+	Truncations      map[string]int        `json:"truncations,omitempty"` // Completions cut by the token limit by call name
+	Notes            []string              `json:"notes,omitempty"`       // Degradations applied to the run
+}
+
+// Meter accumulates the usage of a research run.
+// A nil meter accounts nothing and never limits the run.
+type Meter struct {
+	mu     sync.Mutex
+	limits Limits
+	prices map[string]Price
+	start  time.Time
+	models map[string]*ModelUsage // usage by model
+	pages  int
+	notes  []string // degradations applied to the run
+
+	truncations map[string]int // completions cut by the token limit by call name
+}
+
+// NewMeter creates a new meter with the limits and the price table.
+// The run time is counted from the meter creation.
+func NewMeter(limits Limits, prices map[string]Price) *Meter {
+	return &Meter{
+		limits: limits,
+		prices: prices,
+		start:  time.Now(),
+		models: make(map[string]*ModelUsage),
+
+		truncations: make(map[string]int),
+	}
+}
+
+// AddCompletion accounts a model call.
+func (m *Meter) AddCompletion(model string, promptTokens, completionTokens int64) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.models[model]
+	if !ok {
+		u = &ModelUsage{}
+		m.models[model] = u
+	}
+	u.Calls++
+	u.PromptTokens += promptTokens
+	u.CompletionTokens += completionTokens
+	if price, ok := lookupPrice(m.prices, model); ok {
+		u.Priced = true
+		u.Cost += price.Cost(promptTokens, completionTokens)
+	}
+}
+
+// AddTruncation accounts a completion of the named call cut by the token limit.
+func (m *Meter) AddTruncation(call string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.truncations[call]++
+}
+
+// AllowPages reserves the pages to read out of the n requested.
+// It returns fewer pages when the page budget is running out or the run is reduced.
+func (m *Meter) AllowPages(n int) int {
+	if m == nil {
+		return n
+	}
+
+	level := m.Level()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	allowed := n
+	if level >= LevelReduced && allowed > 1 {
+		allowed = (allowed + 1) / 2
+	}
+	if m.limits.MaxPages > 0 && m.pages+allowed > m.limits.MaxPages {
+		allowed = max(m.limits.MaxPages-m.pages, 0)
+	}
+	m.pages += allowed
+
+	return allowed
+}
+
+// Level returns the degradation level by the share of the tightest budget used.
+func (m *Meter) Level() Level {
+	if m == nil {
+		return LevelNormal
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	used := m.used()
+	switch {
+	case used >= 1:
+		return LevelExhausted
+	case used >= minimalShare:
+		return LevelMinimal
+	case used >= reducedShare:
+		return LevelReduced
+	}
+	return LevelNormal
+}
+
+// Note records a degradation applied to the run, repeated notes are counted once.
+func (m *Meter) Note(note string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, n := range m.notes {
+		if n == note {
+			return
+		}
+	}
+	m.notes = append(m.notes, note)
+}
+
+// Report returns the usage of the run so far, nil for a nil meter.
+func (m *Meter) Report() *Report {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := &Report{
+		Priced:      true,
+		Pages:       m.pages,
+		Duration:    time.Since(m.start),
+		Models:      make(map[string]ModelUsage, len(m.models)),
+		Truncations: make(map[string]int, len(m.truncations)),
+		Notes:       append([]string(nil), m.notes...),
+	}
+	for name, u := range m.models {
+		r.Models[name] = *u
+		r.Calls += u.Calls
+		r.PromptTokens += u.PromptTokens
+		r.CompletionTokens += u.CompletionTokens
+		r.Cost += u.Cost
+		r.Priced = r.Priced && u.Priced
+	}
+	for call, n := range m.truncations {
+		r.Truncations[call] = n
+	}
+	return r
+}
+
+// Summary returns the usage summary of the run.
+func (m *Meter) Summary() string {
+	r := m.Report()
+	if r == nil {
+		return ""
+	}
+
+	names := make([]string, 0, len(r.Models))
+	for name := range r.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: %d calls, %d tokens (%d prompt, %d completion), %s, %d pages, %s\n",
+		r.Calls,
+		r.PromptTokens+r.CompletionTokens,
+		r.PromptTokens,
+		r.CompletionTokens,
+		formatCost(r.Cost, r.Priced),
+		r.Pages,
+		r.Duration.Round(time.Second))
+	if len(names) > 1 {
+		for _, name := range names {
+			u := r.Models[name]
+			fmt.Fprintf(&b, "  %s: %d calls, %d tokens, %s\n",
+				name,
+				u.Calls,
+				u.PromptTokens+u.CompletionTokens,
+				formatCost(u.Cost, u.Priced))
+		}
+	}
+	if len(r.Truncations) > 0 {
+		calls := make([]string, 0, len(r.Truncations))
+		for call, n := range r.Truncations {
+			calls = append(calls, fmt.Sprintf("%s (%d)", call, n))
+		}
+		sort.Strings(calls)
+		fmt.Fprintf(&b, "Token limit hit by: %s\n", strings.Join(calls, ", "))
+	}
+	for _, note := range r.Notes {
+		fmt.Fprintf(&b, "Budget: %s\n", note)
+	}
+
+	return b.String()
+}
+
+// used returns the share of the tightest budget used.
+// The caller must hold the lock.
+func (m *Meter) used() float64 {
+	var tokens int64
+	var cost float64
+	for _, u := range m.models {
+		tokens += u.PromptTokens + u.CompletionTokens
+		cost += u.Cost
+	}
+
+	var used float64
+	if m.limits.MaxTokens > 0 {
+		used = max(used, float64(tokens)/float64(m.limits.MaxTokens))
+	}
+	if m.limits.MaxCost > 0 {
+		used = max(used, cost/m.limits.MaxCost)
+	}
+	if m.limits.MaxPages > 0 {
+		used = max(used, float64(m.pages)/float64(m.limits.MaxPages))
+	}
+	if m.limits.MaxTime > 0 {
+		used = max(used, float64(time.Since(m.start))/float64(m.limits.MaxTime))
+	}
+	return used
+}
+
+// formatCost formats the estimated cost, marking it as partial if some models have no price.
+func formatCost(cost float64, priced bool) string {
+	if !priced {
+		return fmt.Sprintf("~$%.4f (some models have no price)", cost)
+	}
+	return fmt.Sprintf("~$%.4f", cost)
+}
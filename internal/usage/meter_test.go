@@ -0,0 +1,153 @@
+package usage
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMeterLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		tokens int64 // prompt tokens of a gpt-4o call
+		pages  int
+		want   Level
+	}{
+		{name: "no limits", tokens: 1_000_000, pages: 100, want: LevelNormal},
+		{name: "below half", limits: Limits{MaxTokens: 1000}, tokens: 499, want: LevelNormal},
+		{name: "half of tokens", limits: Limits{MaxTokens: 1000}, tokens: 500, want: LevelReduced},
+		{name: "most of tokens", limits: Limits{MaxTokens: 1000}, tokens: 800, want: LevelMinimal},
+		{name: "all tokens", limits: Limits{MaxTokens: 1000}, tokens: 1000, want: LevelExhausted},
+		{name: "cost", limits: Limits{MaxCost: 1}, tokens: 200_000, want: LevelReduced},
+		{name: "pages", limits: Limits{MaxPages: 10}, pages: 8, want: LevelMinimal},
+		{
+			name:   "tightest budget",
+			limits: Limits{MaxTokens: 1_000_000, MaxPages: 10},
+			tokens: 100, pages: 10,
+			want: LevelExhausted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMeter(tt.limits, DefaultPrices)
+			if tt.tokens > 0 {
+				m.AddCompletion("gpt-4o", tt.tokens, 0)
+			}
+			if tt.pages > 0 {
+				m.AllowPages(tt.pages)
+			}
+			if got := m.Level(); got != tt.want {
+				t.Errorf("Level() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeterTimeLimit(t *testing.T) {
+	m := NewMeter(Limits{MaxTime: time.Nanosecond}, nil)
+	time.Sleep(time.Millisecond)
+	if got := m.Level(); got != LevelExhausted {
+		t.Errorf("Level() = %d, want %d", got, LevelExhausted)
+	}
+}
+
+func TestMeterAllowPages(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   Limits
+		tokens   int64
+		requests []int
+		want     []int
+	}{
+		{name: "no limits", requests: []int{5, 5}, want: []int{5, 5}},
+		{name: "page budget", limits: Limits{MaxPages: 12}, requests: []int{5, 5, 5, 5}, want: []int{5, 5, 2, 0}},
+		{name: "reduced run halves the pages", limits: Limits{MaxTokens: 100}, tokens: 50, requests: []int{5, 1}, want: []int{3, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMeter(tt.limits, nil)
+			if tt.tokens > 0 {
+				m.AddCompletion("model", tt.tokens, 0)
+			}
+			for i, n := range tt.requests {
+				if got := m.AllowPages(n); got != tt.want[i] {
+					t.Errorf("AllowPages(%d) #%d = %d, want %d", n, i+1, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMeterReport(t *testing.T) {
+	m := NewMeter(Limits{}, map[string]Price{
+		"gpt-4o":      {Input: 2.5, Output: 10},
+		"gpt-4o-mini": {Input: 0.15, Output: 0.6},
+	})
+	m.AddCompletion("gpt-4o-2024-08-06", 1_000_000, 100_000)
+	m.AddCompletion("gpt-4o-mini-2024-07-18", 1_000_000, 0)
+	m.AddCompletion("unknown-model", 10, 10)
+	m.AddTruncation("write report")
+	m.Note("skipped deeper searches")
+	m.Note("skipped deeper searches")
+
+	r := m.Report()
+	if r.Calls != 3 || r.PromptTokens != 2_000_010 || r.CompletionTokens != 100_010 {
+		t.Errorf("Report() = %d calls, %d prompt, %d completion tokens, want 3, 2000010, 100010",
+			r.Calls, r.PromptTokens, r.CompletionTokens)
+	}
+	// the longest matching price applies to dated model versions
+	if want := 2.5 + 1 + 0.15; math.Abs(r.Cost-want) > 1e-9 {
+		t.Errorf("Report().Cost = %v, want %v", r.Cost, want)
+	}
+	if r.Priced {
+		t.Error("Report().Priced = true with an unpriced model")
+	}
+	if r.Truncations["write report"] != 1 {
+		t.Errorf("Report().Truncations = %v", r.Truncations)
+	}
+	if len(r.Notes) != 1 {
+		t.Errorf("Report().Notes = %v, want a single note", r.Notes)
+	}
+}
+
+func TestNilMeter(t *testing.T) {
+	var m *Meter
+	m.AddCompletion("gpt-4o", 100, 100)
+	m.AddTruncation("call")
+	m.Note("note")
+	if got := m.AllowPages(7); got != 7 {
+		t.Errorf("AllowPages(7) = %d, want 7", got)
+	}
+	if got := m.Level(); got != LevelNormal {
+		t.Errorf("Level() = %d, want %d", got, LevelNormal)
+	}
+	if m.Report() != nil || m.Summary() != "" {
+		t.Error("nil meter reports usage")
+	}
+}
+
+func TestLookupPrice(t *testing.T) {
+	prices := map[string]Price{
+		"gpt-4o":      {Input: 2.5},
+		"gpt-4o-mini": {Input: 0.15},
+	}
+	tests := []struct {
+		model string
+		want  float64
+		ok    bool
+	}{
+		{"gpt-4o", 2.5, true},
+		{"gpt-4o-2024-08-06", 2.5, true},
+		{"gpt-4o-mini-2024-07-18", 0.15, true},
+		{"o1", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := lookupPrice(prices, tt.model)
+		if got.Input != tt.want || ok != tt.ok {
+			t.Errorf("lookupPrice(%q) = %v, %v, want %v, %v", tt.model, got.Input, ok, tt.want, tt.ok)
+		}
+	}
+}
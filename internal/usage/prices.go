@@ -0,0 +1,46 @@
+package usage
+
+import "strings"
+
+// Price is the price of a model in USD per million tokens.
+type Price struct {
+	Input  float64 // Price of a million prompt tokens
+	Output float64 // Price of a million completion tokens
+}
+
+// Cost returns the cost of the tokens in USD.
+func (p Price) Cost(promptTokens, completionTokens int64) float64 {
+	return (float64(promptTokens)*p.Input + float64(completionTokens)*p.Output) / 1e6
+}
+
+// DefaultPrices are the list prices of the common models,
+// the price table in the config extends and overrides them.
+var DefaultPrices = map[string]Price{
+	"gpt-4o":            {Input: 2.5, Output: 10},
+	"gpt-4o-mini":       {Input: 0.15, Output: 0.6},
+	"o1":                {Input: 15, Output: 60},
+	"o1-mini":           {Input: 1.1, Output: 4.4},
+	"o3-mini":           {Input: 1.1, Output: 4.4},
+	"claude-3-5-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4},
+	"claude-3-opus":     {Input: 15, Output: 75},
+
+	"text-embedding-3-small": {Input: 0.02},
+	"text-embedding-3-large": {Input: 0.13},
+	"text-embedding-ada-002": {Input: 0.10},
+}
+
+// lookupPrice returns the price of the model. Providers report dated model
+// versions, so the longest price table entry the model name starts with is used.
+func lookupPrice(prices map[string]Price, model string) (Price, bool) {
+	var match string
+	for name := range prices {
+		if strings.HasPrefix(model, name) && len(name) > len(match) {
+			match = name
+		}
+	}
+	if match == "" {
+		return Price{}, false
+	}
+	return prices[match], true
+}